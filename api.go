@@ -0,0 +1,109 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// callAPI calls the Microcks API at path - relative to /api - with query parameters and an optional
+// JSON body. The JSON response is decoded into out when not nil; an empty response leaves it untouched.
+// When out is an io.Writer, the raw response is copied into it instead.
+// Non-success responses are turned into an *APIError and transport failures into a *ConnectionError.
+func (container *MicrocksContainer) callAPI(ctx context.Context, operation string, method string, path string, query url.Values, in any, out any) (int, error) {
+	// Retrieve API endpoint.
+	httpEndpoint, err := container.HttpEndpoint(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, &ConnectionError{Operation: operation, Err: err}
+	}
+
+	apiURL := httpEndpoint + "/api" + path
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("error encoding %s request: %w", operation, err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, body)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := container.apiHTTPClient().Do(req)
+	if err != nil {
+		return 0, &ConnectionError{Operation: operation, Err: err}
+	}
+	defer response.Body.Close()
+
+	if !isSuccess(response.StatusCode) {
+		return response.StatusCode, newAPIError(operation, "", response)
+	}
+
+	if w, ok := out.(io.Writer); ok {
+		body := &bodyReader{r: response.Body}
+		if _, err := io.Copy(w, body); err != nil {
+			if body.err != nil {
+				return response.StatusCode, &ConnectionError{Operation: operation, Err: body.err}
+			}
+			return response.StatusCode, fmt.Errorf("error writing %s response: %w", operation, err)
+		}
+		return response.StatusCode, nil
+	}
+	if out != nil {
+		payload, err := io.ReadAll(response.Body)
+		if err != nil {
+			return response.StatusCode, &ConnectionError{Operation: operation, Err: err}
+		}
+		payload = bytes.TrimSpace(payload)
+		if len(payload) > 0 && !bytes.Equal(payload, []byte("null")) {
+			if err := json.Unmarshal(payload, out); err != nil {
+				return response.StatusCode, fmt.Errorf("error decoding %s response: %w", operation, err)
+			}
+		}
+	}
+	return response.StatusCode, nil
+}
+
+// bodyReader records the error of reading a response body, to tell it from the errors of writing the body elsewhere.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+var errWrite = errors.New("disk full")
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestCallAPIWriter(t *testing.T) {
+	container := newStubContainer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/export":
+			_, _ = io.WriteString(w, `{"services":[]}`)
+		case "/api/truncated":
+			// Announce more than what is sent, so that reading the body fails.
+			w.Header().Set("Content-Length", "100")
+			_, _ = io.WriteString(w, `{"services":`)
+		}
+	}))
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	_, err := container.callAPI(ctx, OperationExportSnapshot, http.MethodGet, "/export", nil, nil, &out)
+	require.NoError(t, err)
+	require.Equal(t, `{"services":[]}`, out.String())
+
+	// Failing to write the response is not a connection problem.
+	_, err = container.callAPI(ctx, OperationExportSnapshot, http.MethodGet, "/export", nil, nil, failingWriter{})
+	require.ErrorIs(t, err, errWrite)
+	var connErr *ConnectionError
+	require.False(t, errors.As(err, &connErr))
+
+	// Failing to read it is.
+	_, err = container.callAPI(ctx, OperationExportSnapshot, http.MethodGet, "/truncated", nil, nil, io.Discard)
+	require.ErrorAs(t, err, &connErr)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
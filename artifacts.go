@@ -0,0 +1,216 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ArtifactType represents the type of an API artifact Microcks can import.
+type ArtifactType string
+
+const (
+	ArtifactTypeOpenAPI     ArtifactType = "OpenAPI"
+	ArtifactTypeAsyncAPI    ArtifactType = "AsyncAPI"
+	ArtifactTypeGRPC        ArtifactType = "gRPC"
+	ArtifactTypeGraphQL     ArtifactType = "GraphQL"
+	ArtifactTypeSoapUI      ArtifactType = "SoapUI"
+	ArtifactTypePostman     ArtifactType = "Postman"
+	ArtifactTypeHAR         ArtifactType = "HAR"
+	ArtifactTypeAPIMetadata ArtifactType = "APIMetadata"
+	ArtifactTypeAPIExamples ArtifactType = "APIExamples"
+)
+
+// sniffLength is the number of bytes read from a file to detect its artifact type.
+const sniffLength = 64 * 1024
+
+var (
+	openAPIMarker     = regexp.MustCompile(`(?m)^['"]?(openapi|swagger)['"]?\s*:|"(openapi|swagger)"\s*:`)
+	asyncAPIMarker    = regexp.MustCompile(`(?m)^['"]?asyncapi['"]?\s*:|"asyncapi"\s*:`)
+	apiMetadataMarker = regexp.MustCompile(`(?m)^kind\s*:\s*['"]?APIMetadata|"kind"\s*:\s*"APIMetadata"`)
+	apiExamplesMarker = regexp.MustCompile(`(?m)^kind\s*:\s*['"]?APIExamples|"kind"\s*:\s*"APIExamples"`)
+	postmanMarker     = regexp.MustCompile(`schema\.getpostman\.com`)
+	harMarker         = regexp.MustCompile(`"log"\s*:\s*\{`)
+	protoMarker       = regexp.MustCompile(`(?m)^\s*syntax\s*=\s*"proto[23]"`)
+	graphQLMarker     = regexp.MustCompile(`(?m)^\s*(schema\s*\{|type\s+(Query|Mutation|Subscription)\b)`)
+)
+
+// DetectArtifactType sniffs the content of an artifact file to find its type.
+// name is the artifact file name, whose extension is used as a hint.
+// It returns false if content doesn't look like an artifact Microcks can import.
+func DetectArtifactType(name string, content []byte) (ArtifactType, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".proto":
+		return ArtifactTypeGRPC, true
+	case ".graphql", ".graphqls", ".gql":
+		return ArtifactTypeGraphQL, true
+	case ".har":
+		return ArtifactTypeHAR, true
+	}
+
+	switch {
+	case apiMetadataMarker.Match(content):
+		return ArtifactTypeAPIMetadata, true
+	case apiExamplesMarker.Match(content):
+		return ArtifactTypeAPIExamples, true
+	case asyncAPIMarker.Match(content):
+		return ArtifactTypeAsyncAPI, true
+	case openAPIMarker.Match(content):
+		return ArtifactTypeOpenAPI, true
+	case postmanMarker.Match(content):
+		return ArtifactTypePostman, true
+	case strings.Contains(string(content), "soapui-project"):
+		return ArtifactTypeSoapUI, true
+	case harMarker.Match(content) && strings.Contains(string(content), `"entries"`):
+		return ArtifactTypeHAR, true
+	case protoMarker.Match(content):
+		return ArtifactTypeGRPC, true
+	case graphQLMarker.Match(content):
+		return ArtifactTypeGraphQL, true
+	}
+	return "", false
+}
+
+// DefaultIsMainArtifact is the default rule for classifying artifacts found in a directory:
+// contract artifacts (OpenAPI, AsyncAPI, gRPC, GraphQL and SoapUI) are main ones, while
+// Postman collections, HAR files, APIMetadata and APIExamples are secondary ones.
+func DefaultIsMainArtifact(path string, artifactType ArtifactType) bool {
+	switch artifactType {
+	case ArtifactTypeOpenAPI, ArtifactTypeAsyncAPI, ArtifactTypeGRPC, ArtifactTypeGraphQL, ArtifactTypeSoapUI:
+		return true
+	}
+	return false
+}
+
+// ArtifactsDirOptions configures how artifacts are looked up in a directory.
+type ArtifactsDirOptions struct {
+	// Patterns restricts the lookup to files whose base name matches one of these
+	// filepath.Match patterns. All files are considered when empty.
+	Patterns []string
+	// Recursive enables the lookup in sub-directories.
+	Recursive bool
+	// IsMain tells if an artifact must be imported as a main one. Defaults to DefaultIsMainArtifact.
+	IsMain func(path string, artifactType ArtifactType) bool
+}
+
+// ArtifactFile is an artifact file found in a directory.
+type ArtifactFile struct {
+	Path string
+	Type ArtifactType
+	Main bool
+}
+
+// FindArtifacts looks up the artifacts in dir, detecting their type from their content.
+// Files that are not artifacts are ignored. Artifacts are returned in lexical order,
+// main ones first.
+func FindArtifacts(dir string, opts ArtifactsDirOptions) ([]ArtifactFile, error) {
+	isMain := opts.IsMain
+	if isMain == nil {
+		isMain = DefaultIsMainArtifact
+	}
+
+	var mains, secondaries []ArtifactFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && !opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		matched, err := matchesAny(d.Name(), opts.Patterns)
+		if err != nil || !matched {
+			return err
+		}
+
+		content, err := readPrefix(path, sniffLength)
+		if err != nil {
+			return err
+		}
+		artifactType, ok := DetectArtifactType(d.Name(), content)
+		if !ok {
+			return nil
+		}
+
+		artifact := ArtifactFile{Path: path, Type: artifactType, Main: isMain(path, artifactType)}
+		if artifact.Main {
+			mains = append(mains, artifact)
+		} else {
+			secondaries = append(secondaries, artifact)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error looking up artifacts in %s: %w", dir, err)
+	}
+
+	return append(mains, secondaries...), nil
+}
+
+// WithArtifactsDir provides a directory whose artifacts will be imported within the Microcks container.
+// Artifacts are found and classified as main or secondary ones using FindArtifacts. As with
+// WithArtifact, secondary artifacts are imported after all the main ones.
+func WithArtifactsDir(dir string, opts ArtifactsDirOptions) Option {
+	return func(o *options) error {
+		artifacts, err := FindArtifacts(dir, opts)
+		if err != nil {
+			return err
+		}
+		if len(artifacts) == 0 {
+			return fmt.Errorf("no artifact found in %s", dir)
+		}
+
+		for _, artifact := range artifacts {
+			o.plan.add(artifactPhase(artifact.Main), "artifact "+artifact.Path, importArtifactAction(artifact.Path, artifact.Main))
+		}
+		return nil
+	}
+}
+
+func matchesAny(name string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid artifact pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func readPrefix(path string, length int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, length))
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package artifacts builds the APIExamples and APIMetadata secondary artifacts, that add examples,
+// labels or dispatching settings to a service imported from a main artifact.
+// See https://microcks.io/documentation/references/apiexamples/ and
+// https://microcks.io/documentation/references/apimetadata/.
+package artifacts
+
+// apiVersion is the version of the Microcks artifacts format.
+const apiVersion = "mocks.microcks.io/v1alpha1"
+
+// artifactMetadata identifies the service an artifact applies to.
+type artifactMetadata struct {
+	Name        string            `yaml:"name"`
+	Version     string            `yaml:"version"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
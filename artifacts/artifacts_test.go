@@ -0,0 +1,133 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package artifacts_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"microcks.io/testcontainers-go/artifacts"
+	"microcks.io/testcontainers-go/dispatch"
+)
+
+func TestExamples(t *testing.T) {
+	examples := artifacts.Examples("API Pastries", "0.0.1")
+	examples.Operation("GET /pastries/{name}").
+		Example("Kouign-amann",
+			artifacts.Request{Parameters: map[string]string{"name": "Kouign-amann"}},
+			artifacts.Response{Headers: map[string]string{"X-Origin": "Brittany"}, Body: map[string]any{"name": "Kouign-amann", "price": 3.5}}).
+		Example("Sold out",
+			artifacts.Request{Parameters: map[string]string{"name": "Sold out"}},
+			artifacts.Response{Status: http.StatusNotFound, MediaType: "text/plain", Body: "Sold out"})
+	examples.Operation("GET /pastries/{name}").
+		Example("Paris-Brest",
+			artifacts.Request{Parameters: map[string]string{"name": "Paris-Brest"}, Headers: map[string]string{"Accept": "application/json"}},
+			artifacts.Response{Body: map[string]any{"name": "Paris-Brest"}})
+	require.Equal(t, "api-pastries-0.0.1-examples.yaml", examples.FileName())
+
+	content, err := examples.Render()
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &doc))
+	require.Equal(t, "mocks.microcks.io/v1alpha1", doc["apiVersion"])
+	require.Equal(t, "APIExamples", doc["kind"])
+	require.Equal(t, map[string]any{"name": "API Pastries", "version": "0.0.1"}, doc["metadata"])
+
+	operation := doc["operations"].(map[string]any)["GET /pastries/{name}"].(map[string]any)
+	require.Len(t, operation, 3)
+	require.Equal(t, map[string]any{
+		"request": map[string]any{"parameters": map[string]any{"name": "Kouign-amann"}},
+		"response": map[string]any{
+			"headers":   map[string]any{"X-Origin": "Brittany"},
+			"mediaType": "application/json",
+			"status":    "200",
+			"body":      map[string]any{"name": "Kouign-amann", "price": 3.5},
+		},
+	}, operation["Kouign-amann"])
+	require.Equal(t, map[string]any{"mediaType": "text/plain", "status": "404", "body": "Sold out"},
+		operation["Sold out"].(map[string]any)["response"])
+}
+
+func TestExamplesValidation(t *testing.T) {
+	_, err := artifacts.Examples("API Pastries", "").Render()
+	require.ErrorContains(t, err, "service name and version are required")
+	require.ErrorContains(t, err, "no example")
+
+	examples := artifacts.Examples("API Pastries", "0.0.1")
+	examples.Operation("GET /pastries/{name}").
+		Example("Eclair", artifacts.Request{}, artifacts.Response{Status: 42}).
+		Example("Eclair", artifacts.Request{}, artifacts.Response{}).
+		Example("Channel", artifacts.Request{}, artifacts.Response{Body: make(chan int)})
+	_, err = examples.Render()
+	require.ErrorContains(t, err, "invalid status 42")
+	require.ErrorContains(t, err, "name is empty or duplicated")
+	require.ErrorContains(t, err, "response body")
+
+	// Invalid artifacts are not sent to Microcks.
+	status, err := examples.Import(context.Background(), nil)
+	require.ErrorContains(t, err, "invalid status 42")
+	require.Zero(t, status)
+}
+
+func TestMetadata(t *testing.T) {
+	metadata := artifacts.Metadata("API Pastries", "0.0.1").
+		Label("domain", "pastry").
+		Annotation("team", "bakery")
+	metadata.Operation("GET /pastries").
+		Delay(150*time.Millisecond).
+		Dispatcher(dispatch.QueryArgs, dispatch.Params("size"))
+	metadata.Operation("PATCH /pastries/{name}").
+		ParameterConstraint(artifacts.ParameterConstraint{Name: "Authorization", In: "header", Required: true, MustMatchRegexp: `^Bearer\s.+$`})
+	require.Equal(t, "api-pastries-0.0.1-metadata.yaml", metadata.FileName())
+
+	content, err := metadata.Render()
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &doc))
+	require.Equal(t, "APIMetadata", doc["kind"])
+	require.Equal(t, map[string]any{
+		"name":        "API Pastries",
+		"version":     "0.0.1",
+		"labels":      map[string]any{"domain": "pastry"},
+		"annotations": map[string]any{"team": "bakery"},
+	}, doc["metadata"])
+	require.Equal(t, map[string]any{
+		"GET /pastries": map[string]any{"delay": 150, "dispatcher": "QUERY_ARGS", "dispatcherRules": "size"},
+		"PATCH /pastries/{name}": map[string]any{"parameterConstraints": []any{map[string]any{
+			"name": "Authorization", "in": "header", "required": true, "recopy": false, "mustMatchRegexp": `^Bearer\s.+$`,
+		}}},
+	}, doc["operations"])
+}
+
+func TestMetadataValidation(t *testing.T) {
+	metadata := artifacts.Metadata("API Pastries", "0.0.1")
+	metadata.Operation("GET /pastries").
+		Delay(-time.Second).
+		ParameterConstraint(artifacts.ParameterConstraint{Name: "size", In: "body"})
+	_, err := metadata.Render()
+	require.ErrorContains(t, err, "delay -1s is negative")
+	require.ErrorContains(t, err, "parameter constraint requires a name")
+
+	status, err := metadata.Import(context.Background(), nil)
+	require.ErrorContains(t, err, "delay -1s is negative")
+	require.Zero(t, status)
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	microcks "microcks.io/testcontainers-go"
+	"microcks.io/testcontainers-go/internal/artifact"
+)
+
+// ExamplesBuilder builds an APIExamples artifact. Create it with Examples.
+type ExamplesBuilder struct {
+	service    string
+	version    string
+	operations []*ExamplesOperation
+}
+
+// ExamplesOperation gathers the examples of an operation.
+type ExamplesOperation struct {
+	name     string
+	examples []example
+}
+
+type example struct {
+	name     string
+	request  Request
+	response Response
+}
+
+// Request is the request of an example.
+type Request struct {
+	// Parameters are the values of path and query parameters.
+	Parameters map[string]string
+	Headers    map[string]string
+	// Body is the request body. Values other than strings are encoded as JSON.
+	Body any
+}
+
+// Response is the response of an example.
+type Response struct {
+	// Status is the HTTP status. Zero means 200.
+	Status int
+	// MediaType is the media type of Body. It defaults to application/json.
+	MediaType string
+	Headers   map[string]string
+	// Body is the response body. Values other than strings are encoded as JSON.
+	Body any
+}
+
+// Examples starts building an APIExamples artifact, adding examples to the service with the given name and version.
+func Examples(service string, version string) *ExamplesBuilder {
+	return &ExamplesBuilder{service: service, version: version}
+}
+
+// Operation returns the examples of an operation, e.g. "GET /pastries/{name}".
+func (b *ExamplesBuilder) Operation(name string) *ExamplesOperation {
+	for _, operation := range b.operations {
+		if operation.name == name {
+			return operation
+		}
+	}
+	operation := &ExamplesOperation{name: name}
+	b.operations = append(b.operations, operation)
+	return operation
+}
+
+// Example adds an example: Microcks returns resp when receiving a request matching req.
+func (o *ExamplesOperation) Example(name string, req Request, resp Response) *ExamplesOperation {
+	o.examples = append(o.examples, example{name: name, request: req, response: resp})
+	return o
+}
+
+// FileName returns the name of the artifact.
+func (b *ExamplesBuilder) FileName() string {
+	return artifact.FileName(b.service, b.version, "examples")
+}
+
+// Render renders the APIExamples artifact. All the problems found are reported in the error.
+func (b *ExamplesBuilder) Render() ([]byte, error) {
+	var errs []error
+	if b.service == "" || b.version == "" {
+		errs = append(errs, errors.New("service name and version are required"))
+	}
+	if len(b.operations) == 0 {
+		errs = append(errs, errors.New("no example"))
+	}
+
+	apiExamples := examplesArtifact{
+		APIVersion: apiVersion,
+		Kind:       "APIExamples",
+		Metadata:   artifactMetadata{Name: b.service, Version: b.version},
+		Operations: map[string]map[string]exampleObject{},
+	}
+	for _, operation := range b.operations {
+		examples := map[string]exampleObject{}
+		names := map[string]bool{}
+		for _, ex := range operation.examples {
+			object, err := ex.render()
+			if names[ex.name] || ex.name == "" {
+				err = errors.Join(err, errors.New("name is empty or duplicated"))
+			}
+			names[ex.name] = true
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s example %q: %w", operation.name, ex.name, err))
+				continue
+			}
+			examples[ex.name] = object
+		}
+		apiExamples.Operations[operation.name] = examples
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid examples of %s:%s: %w", b.service, b.version, err)
+	}
+	return artifact.RenderYAML(apiExamples)
+}
+
+// Import renders the artifact and imports it as a secondary artifact within the Microcks container.
+// A zero status is returned along with the error when the artifact cannot be rendered.
+func (b *ExamplesBuilder) Import(ctx context.Context, container *microcks.MicrocksContainer) (int, error) {
+	content, err := b.Render()
+	if err != nil {
+		return 0, err
+	}
+	return container.ImportArtifactFromBytes(ctx, b.FileName(), content, false)
+}
+
+func (ex example) render() (exampleObject, error) {
+	var errs []error
+	status := ex.response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < 100 || status > 599 {
+		errs = append(errs, fmt.Errorf("invalid status %d", status))
+	}
+	mediaType := ex.response.MediaType
+	if mediaType == "" && ex.response.Body != nil {
+		mediaType = "application/json"
+	}
+
+	requestBody, err := artifact.JSONValue(ex.request.Body)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("request body: %w", err))
+	}
+	responseBody, err := artifact.JSONValue(ex.response.Body)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("response body: %w", err))
+	}
+
+	return exampleObject{
+		Request: requestObject{
+			Parameters: ex.request.Parameters,
+			Headers:    ex.request.Headers,
+			Body:       requestBody,
+		},
+		Response: responseObject{
+			Headers:   ex.response.Headers,
+			MediaType: mediaType,
+			Status:    strconv.Itoa(status),
+			Body:      responseBody,
+		},
+	}, errors.Join(errs...)
+}
+
+type examplesArtifact struct {
+	APIVersion string                              `yaml:"apiVersion"`
+	Kind       string                              `yaml:"kind"`
+	Metadata   artifactMetadata                    `yaml:"metadata"`
+	Operations map[string]map[string]exampleObject `yaml:"operations"`
+}
+
+type exampleObject struct {
+	Request  requestObject  `yaml:"request"`
+	Response responseObject `yaml:"response"`
+}
+
+type requestObject struct {
+	Parameters map[string]string `yaml:"parameters,omitempty"`
+	Headers    map[string]string `yaml:"headers,omitempty"`
+	Body       any               `yaml:"body,omitempty"`
+}
+
+type responseObject struct {
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	MediaType string            `yaml:"mediaType,omitempty"`
+	Status    string            `yaml:"status"`
+	Body      any               `yaml:"body,omitempty"`
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	microcks "microcks.io/testcontainers-go"
+	"microcks.io/testcontainers-go/internal/artifact"
+)
+
+// MetadataBuilder builds an APIMetadata artifact. Create it with Metadata.
+type MetadataBuilder struct {
+	service     string
+	version     string
+	labels      map[string]string
+	annotations map[string]string
+	operations  []*MetadataOperation
+}
+
+// MetadataOperation holds the settings of an operation.
+type MetadataOperation struct {
+	name                 string
+	delay                time.Duration
+	dispatcher           string
+	dispatcherRules      string
+	parameterConstraints []ParameterConstraint
+}
+
+// ParameterConstraint is a constraint on a request parameter, checked by Microcks when mocking.
+type ParameterConstraint struct {
+	Name string
+	// In is where the parameter is found: "path", "query" or "header".
+	In       string
+	Required bool
+	// Recopy copies the parameter from the request into the response.
+	Recopy          bool
+	MustMatchRegexp string
+}
+
+// Metadata starts building an APIMetadata artifact for the service with the given name and version.
+func Metadata(service string, version string) *MetadataBuilder {
+	return &MetadataBuilder{service: service, version: version}
+}
+
+// Label sets a label on the service.
+func (b *MetadataBuilder) Label(key string, value string) *MetadataBuilder {
+	if b.labels == nil {
+		b.labels = map[string]string{}
+	}
+	b.labels[key] = value
+	return b
+}
+
+// Annotation sets an annotation on the service.
+func (b *MetadataBuilder) Annotation(key string, value string) *MetadataBuilder {
+	if b.annotations == nil {
+		b.annotations = map[string]string{}
+	}
+	b.annotations[key] = value
+	return b
+}
+
+// Operation returns the settings of an operation, e.g. "GET /pastries/{name}".
+func (b *MetadataBuilder) Operation(name string) *MetadataOperation {
+	for _, operation := range b.operations {
+		if operation.name == name {
+			return operation
+		}
+	}
+	operation := &MetadataOperation{name: name}
+	b.operations = append(b.operations, operation)
+	return operation
+}
+
+// Delay sets the delay applied before returning responses, with a millisecond precision.
+func (o *MetadataOperation) Delay(delay time.Duration) *MetadataOperation {
+	o.delay = delay
+	return o
+}
+
+// Dispatcher sets the dispatcher, and its rules, picking the response of the operation. See the dispatch package.
+func (o *MetadataOperation) Dispatcher(dispatcher string, rules string) *MetadataOperation {
+	o.dispatcher = dispatcher
+	o.dispatcherRules = rules
+	return o
+}
+
+// ParameterConstraint adds a constraint on a request parameter.
+func (o *MetadataOperation) ParameterConstraint(constraint ParameterConstraint) *MetadataOperation {
+	o.parameterConstraints = append(o.parameterConstraints, constraint)
+	return o
+}
+
+// FileName returns the name of the artifact.
+func (b *MetadataBuilder) FileName() string {
+	return artifact.FileName(b.service, b.version, "metadata")
+}
+
+// Render renders the APIMetadata artifact. All the problems found are reported in the error.
+func (b *MetadataBuilder) Render() ([]byte, error) {
+	var errs []error
+	if b.service == "" || b.version == "" {
+		errs = append(errs, errors.New("service name and version are required"))
+	}
+
+	apiMetadata := metadataArtifact{
+		APIVersion: apiVersion,
+		Kind:       "APIMetadata",
+		Metadata:   artifactMetadata{Name: b.service, Version: b.version, Labels: b.labels, Annotations: b.annotations},
+	}
+	for _, operation := range b.operations {
+		if operation.delay < 0 {
+			errs = append(errs, fmt.Errorf("%s: delay %s is negative", operation.name, operation.delay))
+		}
+		object := operationMetadata{
+			Delay:           operation.delay.Milliseconds(),
+			Dispatcher:      operation.dispatcher,
+			DispatcherRules: operation.dispatcherRules,
+		}
+		for _, constraint := range operation.parameterConstraints {
+			if constraint.Name == "" || !slices.Contains([]string{"path", "query", "header"}, constraint.In) {
+				errs = append(errs, fmt.Errorf("%s: parameter constraint requires a name and a location among path, query and header", operation.name))
+			}
+			object.ParameterConstraints = append(object.ParameterConstraints, parameterConstraintObject(constraint))
+		}
+		if apiMetadata.Operations == nil {
+			apiMetadata.Operations = map[string]operationMetadata{}
+		}
+		apiMetadata.Operations[operation.name] = object
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid metadata of %s:%s: %w", b.service, b.version, err)
+	}
+	return artifact.RenderYAML(apiMetadata)
+}
+
+// Import renders the artifact and imports it as a secondary artifact within the Microcks container.
+// A zero status is returned along with the error when the artifact cannot be rendered.
+func (b *MetadataBuilder) Import(ctx context.Context, container *microcks.MicrocksContainer) (int, error) {
+	content, err := b.Render()
+	if err != nil {
+		return 0, err
+	}
+	return container.ImportArtifactFromBytes(ctx, b.FileName(), content, false)
+}
+
+type metadataArtifact struct {
+	APIVersion string                       `yaml:"apiVersion"`
+	Kind       string                       `yaml:"kind"`
+	Metadata   artifactMetadata             `yaml:"metadata"`
+	Operations map[string]operationMetadata `yaml:"operations,omitempty"`
+}
+
+type operationMetadata struct {
+	Delay                int64                       `yaml:"delay,omitempty"`
+	Dispatcher           string                      `yaml:"dispatcher,omitempty"`
+	DispatcherRules      string                      `yaml:"dispatcherRules,omitempty"`
+	ParameterConstraints []parameterConstraintObject `yaml:"parameterConstraints,omitempty"`
+}
+
+type parameterConstraintObject struct {
+	Name            string `yaml:"name"`
+	In              string `yaml:"in"`
+	Required        bool   `yaml:"required"`
+	Recopy          bool   `yaml:"recopy"`
+	MustMatchRegexp string `yaml:"mustMatchRegexp,omitempty"`
+}
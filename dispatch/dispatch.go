@@ -0,0 +1,145 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dispatch names the Microcks dispatchers and builds their rules. A dispatcher picks the
+// response a mocked operation returns; see https://microcks.io/documentation/explanations/dispatching/.
+package dispatch
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Dispatchers supported by Microcks.
+const (
+	// Sequence picks the response matching the combination of all the parameters, without rules.
+	Sequence = "SEQUENCE"
+	// Script picks the response named by a Groovy script, the rules.
+	Script = "SCRIPT"
+	// JSONBody picks the response from a JSON Pointer evaluated on the request body. See JSONBodyRules.
+	JSONBody = "JSON_BODY"
+	// QueryMatch picks the response from an XPath expression evaluated on the request body.
+	QueryMatch = "QUERY_MATCH"
+	// QueryArgs picks the response from query parameters. See Params.
+	QueryArgs = "QUERY_ARGS"
+	// QueryHeader picks the response from request headers. See Params.
+	QueryHeader = "QUERY_HEADER"
+	// URIParams picks the response from query parameters of the URI. See Params.
+	URIParams = "URI_PARAMS"
+	// URIParts picks the response from path parameters. See Params.
+	URIParts = "URI_PARTS"
+	// URIElements picks the response from both path and query parameters. See Params.
+	URIElements = "URI_ELEMENTS"
+	// Random picks a random response, without rules.
+	Random = "RANDOM"
+	// Fallback applies another dispatcher and returns a fallback response when nothing matches. See FallbackRules.
+	Fallback = "FALLBACK"
+	// Proxy forwards every request to a real endpoint, the rules.
+	Proxy = "PROXY"
+	// ProxyFallback applies another dispatcher and forwards the request to a real endpoint when nothing matches.
+	// See ProxyFallbackRules.
+	ProxyFallback = "PROXY_FALLBACK"
+)
+
+// Params builds the rules of the QueryArgs, QueryHeader, URIParams, URIParts and URIElements dispatchers:
+// the names of the parameters whose values identify the response.
+func Params(names ...string) string {
+	return strings.Join(names, " && ")
+}
+
+// FallbackRules builds the rules of the Fallback dispatcher: dispatcher and rules are applied first,
+// and the response named fallback is returned when nothing matches.
+func FallbackRules(dispatcher string, rules string, fallback string) string {
+	return marshal(map[string]string{"dispatcher": dispatcher, "dispatcherRules": rules, "fallback": fallback})
+}
+
+// ProxyFallbackRules builds the rules of the ProxyFallback dispatcher: dispatcher and rules are applied
+// first, and the request is forwarded to proxyURL when nothing matches.
+func ProxyFallbackRules(dispatcher string, rules string, proxyURL string) string {
+	return marshal(map[string]string{"dispatcher": dispatcher, "dispatcherRules": rules, "proxyUrl": proxyURL})
+}
+
+// JSONBodyBuilder builds the rules of the JSONBody dispatcher. Create it with JSONBodyRules.
+type JSONBodyBuilder struct {
+	exp      string
+	operator string
+	cases    map[string]string
+}
+
+// JSONBodyRules starts building rules for the JSONBody dispatcher, evaluating the JSON Pointer exp,
+// e.g. "/country", on request bodies. Values are compared with the equals operator unless another
+// one is chosen.
+func JSONBodyRules(exp string) *JSONBodyBuilder {
+	return &JSONBodyBuilder{exp: exp, operator: "equals", cases: map[string]string{}}
+}
+
+// Equals compares the evaluated value with the cases values.
+func (b *JSONBodyBuilder) Equals() *JSONBodyBuilder {
+	b.operator = "equals"
+	return b
+}
+
+// Range checks the evaluated value is within the cases ranges, like "[18;99]" or "]0;18[".
+func (b *JSONBodyBuilder) Range() *JSONBodyBuilder {
+	b.operator = "range"
+	return b
+}
+
+// Size checks the size of the evaluated array is within the cases ranges, like "[1;10]".
+func (b *JSONBodyBuilder) Size() *JSONBodyBuilder {
+	b.operator = "size"
+	return b
+}
+
+// Regexp matches the evaluated value with the cases regular expressions.
+func (b *JSONBodyBuilder) Regexp() *JSONBodyBuilder {
+	b.operator = "regexp"
+	return b
+}
+
+// Presence checks whether the evaluated value is present, with the "found" case.
+func (b *JSONBodyBuilder) Presence() *JSONBodyBuilder {
+	b.operator = "presence"
+	return b
+}
+
+// Case returns the response named response when the evaluated value matches value.
+func (b *JSONBodyBuilder) Case(value string, response string) *JSONBodyBuilder {
+	b.cases[value] = response
+	return b
+}
+
+// Default returns the response named response when no case matches.
+func (b *JSONBodyBuilder) Default(response string) *JSONBodyBuilder {
+	b.cases["default"] = response
+	return b
+}
+
+// String returns the rules.
+func (b *JSONBodyBuilder) String() string {
+	return marshal(struct {
+		Exp      string            `json:"exp"`
+		Operator string            `json:"operator"`
+		Cases    map[string]string `json:"cases"`
+	}{b.exp, b.operator, b.cases})
+}
+
+// marshal encodes rules as JSON. Encoding strings can't fail, and maps keys are sorted
+// so that rules are stable.
+func marshal(rules any) string {
+	payload, _ := json.Marshal(rules)
+	return string(payload)
+}
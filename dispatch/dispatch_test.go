@@ -0,0 +1,39 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dispatch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"microcks.io/testcontainers-go/dispatch"
+)
+
+func TestRules(t *testing.T) {
+	require.Equal(t, "name", dispatch.Params("name"))
+	require.Equal(t, "size && page", dispatch.Params("size", "page"))
+
+	require.JSONEq(t, `{"dispatcher": "URI_PARTS", "dispatcherRules": "name", "fallback": "Millefeuille"}`,
+		dispatch.FallbackRules(dispatch.URIParts, dispatch.Params("name"), "Millefeuille"))
+	require.JSONEq(t, `{"dispatcher": "URI_PARTS", "dispatcherRules": "name", "proxyUrl": "http://pastries:8080"}`,
+		dispatch.ProxyFallbackRules(dispatch.URIParts, "name", "http://pastries:8080"))
+
+	rules := dispatch.JSONBodyRules("/country").Case("Belgium", "Accepted").Default("Rejected").String()
+	require.Equal(t, `{"exp":"/country","operator":"equals","cases":{"Belgium":"Accepted","default":"Rejected"}}`, rules)
+
+	rules = dispatch.JSONBodyRules("/age").Range().Case("[18;99]", "Adult").Case("[0;18[", "Minor").String()
+	require.JSONEq(t, `{"exp": "/age", "operator": "range", "cases": {"[18;99]": "Adult", "[0;18[": "Minor"}}`, rules)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RestMockEndpointURL get the exposed mock endpoint URL for a REST Service. Unlike RestMockEndpoint,
+// the service name and version are escaped, e.g. "API Pastries" becomes "API%20Pastries".
+func (container *MicrocksContainer) RestMockEndpointURL(ctx context.Context, service string, version string) (*url.URL, error) {
+	return container.mockEndpointURL(ctx, "rest", service, version, nil)
+}
+
+// ValidatingRestMockEndpointURL get the exposed mock endpoint URL - with request validation enabled - for a REST Service.
+func (container *MicrocksContainer) ValidatingRestMockEndpointURL(ctx context.Context, service string, version string) (*url.URL, error) {
+	return container.mockEndpointURL(ctx, "rest-valid", service, version, nil)
+}
+
+// SoapMockEndpointURL get the exposed mock endpoint URL for a SOAP Service.
+func (container *MicrocksContainer) SoapMockEndpointURL(ctx context.Context, service string, version string) (*url.URL, error) {
+	return container.mockEndpointURL(ctx, "soap", service, version, nil)
+}
+
+// ValidatingSoapMockEndpointURL get the exposed mock endpoint URL - with request validation enabled - for a SOAP Service.
+// Validation is enabled by the validate query parameter: add yours with Query() and Values.Encode() to keep it.
+func (container *MicrocksContainer) ValidatingSoapMockEndpointURL(ctx context.Context, service string, version string) (*url.URL, error) {
+	return container.mockEndpointURL(ctx, "soap", service, version, url.Values{"validate": {"true"}})
+}
+
+// GraphQLMockEndpointURL get the exposed mock endpoint URL for a GraphQL Service.
+func (container *MicrocksContainer) GraphQLMockEndpointURL(ctx context.Context, service string, version string) (*url.URL, error) {
+	return container.mockEndpointURL(ctx, "graphql", service, version, nil)
+}
+
+// MockHTTPClient returns an HTTP client calling the mocks of a REST Service: relative request URLs,
+// like "/pastries/Millefeuille?size=L", are resolved against RestMockEndpointURL. Absolute URLs are left untouched.
+// The client uses the transport of the client set with WithHTTPClient, if any.
+func (container *MicrocksContainer) MockHTTPClient(ctx context.Context, service string, version string) (*http.Client, error) {
+	base, err := container.RestMockEndpointURL(ctx, service, version)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving mock endpoint of %s:%s: %w", service, version, err)
+	}
+
+	next := container.apiHTTPClient().Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &http.Client{Transport: &mockTransport{base: base, next: next}}, nil
+}
+
+// mockEndpointURL returns the URL of a mock endpoint, escaping the service name and version as path segments.
+func (container *MicrocksContainer) mockEndpointURL(ctx context.Context, prefix string, service string, version string, query url.Values) (*url.URL, error) {
+	endpoint, err := container.HttpEndpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = "/" + prefix + "/" + service + "/" + version
+	u.RawPath = "/" + prefix + "/" + url.PathEscape(service) + "/" + url.PathEscape(version)
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// mockTransport resolves relative request URLs against a mock endpoint.
+type mockTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.IsAbs() {
+		return t.next.RoundTrip(req)
+	}
+
+	resolved := req.Clone(req.Context())
+	resolved.URL = resolveMockURL(t.base, req.URL)
+	resolved.Host = resolved.URL.Host
+	return t.next.RoundTrip(resolved)
+}
+
+// resolveMockURL appends the path of ref to the one of base, and merges their queries. Unlike
+// URL.ResolveReference, "/pastries" doesn't replace the base path.
+func resolveMockURL(base *url.URL, ref *url.URL) *url.URL {
+	resolved := *base
+	resolved.Path = strings.TrimSuffix(base.Path, "/") + "/" + strings.TrimPrefix(ref.Path, "/")
+	resolved.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + "/" + strings.TrimPrefix(ref.EscapedPath(), "/")
+
+	query := base.Query()
+	for key, values := range ref.Query() {
+		query[key] = append(query[key], values...)
+	}
+	resolved.RawQuery = query.Encode()
+	resolved.Fragment = ref.Fragment
+	return &resolved
+}
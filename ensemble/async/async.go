@@ -65,6 +65,7 @@ func RunContainer(ctx context.Context, microcksHostPort string, opts ...testcont
 }
 
 // Run creates an instance of the MicrocksAsyncMinionContainer type.
+// If the container has been created but failed to get ready, it's returned along with the error.
 func Run(ctx context.Context, image string, microcksHostPort string, opts ...testcontainers.ContainerCustomizer) (*MicrocksAsyncMinionContainer, error) {
 	req := testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
@@ -85,6 +86,10 @@ func Run(ctx context.Context, image string, microcksHostPort string, opts ...tes
 
 	container, err := testcontainers.GenericContainer(ctx, req)
 	if err != nil {
+		if container != nil {
+			// Container has been created but is not ready: return it so that it can be terminated.
+			return &MicrocksAsyncMinionContainer{Container: container}, err
+		}
 		return nil, err
 	}
 
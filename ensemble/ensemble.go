@@ -17,7 +17,9 @@ package ensemble
 
 import (
 	"context"
+	"net/http"
 	"strings"
+	"testing"
 
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
@@ -88,31 +90,36 @@ func (ec *MicrocksContainersEnsemble) GetAsyncMinionContainer() *async.MicrocksA
 }
 
 // Terminate helps to terminate all containers.
+// Containers that have not been started are skipped.
 func (ec *MicrocksContainersEnsemble) Terminate(ctx context.Context) error {
-	// Main Microcks container.
-	if err := ec.microcksContainer.Terminate(ctx); err != nil {
-		return err
-	}
-
-	// Postman container.
-	if ec.postmanEnabled {
-		if err := ec.postmanContainer.Terminate(ctx); err != nil {
+	for _, container := range ec.containers() {
+		if err := container.Terminate(ctx); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	// Async Microcks minion container.
-	if ec.asyncEnabled {
-		if err := ec.asyncMinionContainer.Terminate(ctx); err != nil {
-			return err
-		}
+// containers returns the started containers of the ensemble: main Microcks container,
+// Postman container and Async Microcks minion container.
+func (ec *MicrocksContainersEnsemble) containers() []testcontainers.Container {
+	var containers []testcontainers.Container
+	if ec.microcksContainer != nil {
+		containers = append(containers, ec.microcksContainer)
 	}
-
-	return nil
+	if ec.postmanEnabled && ec.postmanContainer != nil {
+		containers = append(containers, ec.postmanContainer)
+	}
+	if ec.asyncEnabled && ec.asyncMinionContainer != nil {
+		containers = append(containers, ec.asyncMinionContainer)
+	}
+	return containers
 }
 
 // RunContainers creates instances of the Microcks Ensemble.
 // Using sequential start to avoid resource contention on CI systems with weaker hardware.
+// If a container fails to start, the ensemble is returned along with the error so that
+// already started containers can be terminated.
 func RunContainers(ctx context.Context, opts ...Option) (*MicrocksContainersEnsemble, error) {
 	var err error
 
@@ -147,14 +154,14 @@ func RunContainers(ctx context.Context, opts ...Option) (*MicrocksContainersEnse
 	}
 	ensemble.microcksContainer, err = microcks.Run(ctx, ensemble.microcksContainerImage, ensemble.microcksContainerOptions.list...)
 	if err != nil {
-		return nil, err
+		return ensemble, err
 	}
 
 	// Start Postman container if enabled.
 	if ensemble.postmanEnabled {
 		ensemble.postmanContainer, err = postman.Run(ctx, ensemble.postmanContainerImage, ensemble.postmanContainerOptions.list...)
 		if err != nil {
-			return nil, err
+			return ensemble, err
 		}
 	}
 
@@ -163,13 +170,40 @@ func RunContainers(ctx context.Context, opts ...Option) (*MicrocksContainersEnse
 		microcksHostPort := strings.Join([]string{microcks.DefaultNetworkAlias, ":8080"}, "")
 		ensemble.asyncMinionContainer, err = async.Run(ctx, ensemble.asyncMinionContainerImage, microcksHostPort, ensemble.asyncMinionContainerOptions.list...)
 		if err != nil {
-			return nil, err
+			return ensemble, err
 		}
 	}
 
 	return ensemble, nil
 }
 
+// RunContainersT creates instances of the Microcks Ensemble for test t. The test fails if a container
+// cannot be started, and containers and network are terminated at the end of the test. If the test failed,
+// the logs of every container are dumped first, and containers are kept alive if microcks.KeepOnFailureEnv is true.
+func RunContainersT(t testing.TB, opts ...Option) *MicrocksContainersEnsemble {
+	t.Helper()
+
+	ensemble, err := RunContainers(context.Background(), opts...)
+	if ensemble != nil {
+		// Cleanups run in reverse order: network is removed once containers are terminated.
+		if ensemble.network != nil {
+			t.Cleanup(func() {
+				if t.Failed() && microcks.KeepOnFailure() {
+					return
+				}
+				if err := ensemble.network.Remove(context.Background()); err != nil {
+					t.Logf("cannot remove ensemble network: %v", err)
+				}
+			})
+		}
+		microcks.CleanupT(t, ensemble.containers()...)
+	}
+	if err != nil {
+		t.Fatalf("cannot start Microcks ensemble: %v", err)
+	}
+	return ensemble
+}
+
 // WithDebugLogLevel sets Microcks and Async Minion log levels to DEBUG.
 func WithDebugLogLevel() Option {
 	return func(e *MicrocksContainersEnsemble) error {
@@ -280,6 +314,15 @@ func WithSecondaryArtifact(artifactFilePath string) Option {
 	}
 }
 
+// WithArtifactsDir provides a directory whose artifacts will be imported within the Microcks container.
+// Artifacts are classified as main or secondary ones; secondary ones are imported after all the main ones.
+func WithArtifactsDir(dir string, opts microcks.ArtifactsDirOptions) Option {
+	return func(e *MicrocksContainersEnsemble) error {
+		e.microcksContainerOptions.Add(microcks.WithArtifactsDir(dir, opts))
+		return nil
+	}
+}
+
 // WithSnapshot provides paths to local repository snapshots that will be imported within the Microcks container.
 func WithSnapshot(snapshotFilePath string) Option {
 	return func(e *MicrocksContainersEnsemble) error {
@@ -368,3 +411,11 @@ func WithSecret(s client.Secret) Option {
 		return nil
 	}
 }
+
+// WithHTTPClient sets the HTTP client used to call the Microcks API.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(e *MicrocksContainersEnsemble) error {
+		e.microcksContainerOptions.Add(microcks.WithHTTPClient(httpClient))
+		return nil
+	}
+}
@@ -44,6 +44,7 @@ func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomize
 }
 
 // Run creates an instance of the PostmanContainer type.
+// If the container has been created but failed to get ready, it's returned along with the error.
 func Run(ctx context.Context, image string, opts ...testcontainers.ContainerCustomizer) (*PostmanContainer, error) {
 	req := testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
@@ -60,6 +61,10 @@ func Run(ctx context.Context, image string, opts ...testcontainers.ContainerCust
 
 	container, err := testcontainers.GenericContainer(ctx, req)
 	if err != nil {
+		if container != nil {
+			// Container has been created but is not ready: return it so that it can be terminated.
+			return &PostmanContainer{Container: container}, err
+		}
 		return nil, err
 	}
 
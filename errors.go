@@ -0,0 +1,124 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Operations reported by APIError and ConnectionError.
+const (
+	OperationImportArtifact      = "import artifact"
+	OperationImportSnapshot      = "import snapshot"
+	OperationExportSnapshot      = "export snapshot"
+	OperationDownloadArtifact    = "download artifact"
+	OperationCreateSecret        = "create secret"
+	OperationCreateTest          = "create test"
+	OperationGetTestResult       = "get test result"
+	OperationRegisterWebhook     = "register webhook"
+	OperationGetInvocationStats  = "get invocation stats"
+	OperationListServices        = "list services"
+	OperationGetServiceResources = "get service resources"
+	OperationGetService          = "get service"
+	OperationUpdateOperation     = "update operation"
+	OperationDeleteService       = "delete service"
+	OperationListSecrets         = "list secrets"
+	OperationUpdateSecret        = "update secret"
+	OperationDeleteSecret        = "delete secret"
+	OperationListWebhooks        = "list webhooks"
+	OperationUnregisterWebhook   = "unregister webhook"
+)
+
+// APIError is returned when Microcks answers an API call with a non-success status.
+// Use errors.As to inspect it.
+type APIError struct {
+	// Operation is the operation that failed (one of the Operation* constants).
+	Operation string
+	// StatusCode is the HTTP status returned by Microcks.
+	StatusCode int
+	// Body is the raw response body, usually holding Microcks' explanation.
+	Body string
+	// Artifact is the artifact path or URL involved, if any.
+	Artifact string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("microcks %s failed with status %d", e.Operation, e.StatusCode)
+	if e.Artifact != "" {
+		msg += fmt.Sprintf(" for %s", e.Artifact)
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// ConnectionError is returned when the Microcks API cannot be reached at all,
+// e.g. because the container is not running or the request timed out.
+// Use errors.As to inspect it.
+type ConnectionError struct {
+	// Operation is the operation that failed (one of the Operation* constants).
+	Operation string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("microcks %s failed, cannot reach Microcks API: %v", e.Operation, e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// TestTimeoutError is returned by TestEndpoint when a test is still in progress once its timeout
+// has elapsed or the context is done. Use errors.As to inspect it, or errors.Is with
+// context.DeadlineExceeded or context.Canceled.
+type TestTimeoutError struct {
+	// TestResultId is the identifier of the test result in Microcks.
+	TestResultId string
+	// Timeout is how long the test has been waited for.
+	Timeout time.Duration
+	// Err is the context error that stopped the wait.
+	Err error
+}
+
+func (e *TestTimeoutError) Error() string {
+	return fmt.Sprintf("microcks test %s still in progress after %s: %v", e.TestResultId, e.Timeout, e.Err)
+}
+
+func (e *TestTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// isSuccess tells if an HTTP status is a success one.
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// newAPIError builds an APIError from a non-success response, consuming its body.
+func newAPIError(operation string, artifact string, response *http.Response) *APIError {
+	body, _ := io.ReadAll(response.Body)
+	return &APIError{
+		Operation:  operation,
+		StatusCode: response.StatusCode,
+		Body:       string(body),
+		Artifact:   artifact,
+	}
+}
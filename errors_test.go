@@ -0,0 +1,112 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	client "microcks.io/go-client"
+)
+
+// inProgressTest serves a test that never completes. Every poll of its result calls polled.
+func inProgressTest(t *testing.T, polled func()) http.Handler {
+	result := client.TestResult{Id: "test-1", InProgress: true}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/tests":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/tests/test-1":
+			polled()
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("cannot encode test result: %v", err)
+			return
+		}
+	})
+}
+
+func TestTestTimeoutError(t *testing.T) {
+	var err error = &TestTimeoutError{TestResultId: "test-1", Timeout: 2 * time.Second, Err: context.DeadlineExceeded}
+	require.EqualError(t, err, "microcks test test-1 still in progress after 2s: context deadline exceeded")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotErrorIs(t, err, context.Canceled)
+
+	var timeoutErr *TestTimeoutError
+	require.ErrorAs(t, errors.Join(errors.New("wrapped"), err), &timeoutErr)
+	require.Equal(t, "test-1", timeoutErr.TestResultId)
+	require.Equal(t, 2*time.Second, timeoutErr.Timeout)
+}
+
+func TestTestEndpointTimeout(t *testing.T) {
+	container := newStubContainer(t, inProgressTest(t, func() {}))
+
+	// Tests are waited for their timeout plus an extra second.
+	start := time.Now()
+	result, err := container.TestEndpoint(context.Background(), &client.TestRequest{
+		ServiceId: "API Pastries:0.0.1",
+		Timeout:   100,
+	})
+	require.GreaterOrEqual(t, time.Since(start), 1100*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeoutErr *TestTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, "test-1", timeoutErr.TestResultId)
+	require.Equal(t, 1100*time.Millisecond, timeoutErr.Timeout)
+
+	// The last polled result is returned along with the error.
+	require.NotNil(t, result)
+	require.Equal(t, "test-1", result.Id)
+	require.True(t, result.InProgress)
+}
+
+func TestTestEndpointCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel while polling, long before the test timeout.
+	var polls int
+	container := newStubContainer(t, inProgressTest(t, func() {
+		if polls++; polls == 3 {
+			cancel()
+		}
+	}))
+
+	result, err := container.TestEndpoint(ctx, &client.TestRequest{
+		ServiceId: "API Pastries:0.0.1",
+		Timeout:   60000,
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeoutErr *TestTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, "test-1", timeoutErr.TestResultId)
+
+	require.NotNil(t, result)
+	require.True(t, result.InProgress)
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"microcks.io/testcontainers-go/dispatch"
+)
+
+// faultProfiles numbers the applied fault profiles, so that each one counts calls from zero.
+var faultProfiles atomic.Int64
+
+// SetOperationDelay sets the delay applied by Microcks before returning the responses of a mocked operation,
+// with a millisecond precision. The returned RestoreFunc restores the original delay; register it with Cleanup
+// to restore at the end of a test.
+// It returns an error wrapping ErrServiceNotFound or ErrOperationNotFound if there's no such operation.
+func (container *MicrocksContainer) SetOperationDelay(ctx context.Context, serviceName string, serviceVersion string, operationName string, delay time.Duration) (RestoreFunc, error) {
+	if delay < 0 {
+		return nil, fmt.Errorf("delay %s is negative", delay)
+	}
+
+	original, err := container.updateOperation(ctx, serviceName, serviceVersion, operationName, func(o *operationOverride) {
+		o.DefaultDelay = delay.Milliseconds()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return container.restoreOperation(serviceName, serviceVersion, operationName, func(o *operationOverride) {
+		o.DefaultDelay = original.DefaultDelay
+	}), nil
+}
+
+// FaultProfile scripts the responses of a mocked operation to simulate failures, e.g. returning
+// the 503 example for a few calls before recovering.
+type FaultProfile struct {
+	// Fault is the name of the response - the example - returned by failing calls.
+	Fault string
+	// Calls is the number of failing calls. Zero makes every call fail.
+	Calls int
+	// Recovery is the name of the response returned once Calls calls have failed.
+	Recovery string
+}
+
+// SetOperationFaults applies profile to a mocked operation, using a script dispatcher that counts calls.
+// The returned RestoreFunc restores the original dispatcher; register it with Cleanup to restore at the
+// end of a test. Applying a profile again counts calls from zero.
+// It returns an error wrapping ErrServiceNotFound or ErrOperationNotFound if there's no such operation.
+func (container *MicrocksContainer) SetOperationFaults(ctx context.Context, serviceName string, serviceVersion string, operationName string, profile FaultProfile) (RestoreFunc, error) {
+	if err := profile.validate(); err != nil {
+		return nil, fmt.Errorf("invalid fault profile: %w", err)
+	}
+	return container.SetOperationDispatcher(ctx, serviceName, serviceVersion, operationName, dispatch.Script, profile.script())
+}
+
+func (p FaultProfile) validate() error {
+	var errs []error
+	if p.Fault == "" {
+		errs = append(errs, errors.New("fault response is required"))
+	}
+	if p.Calls < 0 {
+		errs = append(errs, fmt.Errorf("calls %d is negative", p.Calls))
+	}
+	if p.Calls > 0 && p.Recovery == "" {
+		errs = append(errs, errors.New("recovery response is required when calls are limited"))
+	}
+	return errors.Join(errs...)
+}
+
+// script returns the Groovy script of the dispatcher, counting calls in the Microcks store.
+func (p FaultProfile) script() string {
+	if p.Calls == 0 {
+		return "return " + groovyString(p.Fault)
+	}
+
+	counter := groovyString(fmt.Sprintf("testcontainers-faults-%d", faultProfiles.Add(1)))
+	return strings.Join([]string{
+		"def calls = (store.get(" + counter + ") ?: '0') as Integer",
+		"store.put(" + counter + ", String.valueOf(calls + 1))",
+		fmt.Sprintf("return calls < %d ? %s : %s", p.Calls, groovyString(p.Fault), groovyString(p.Recovery)),
+	}, "\n")
+}
+
+// groovyString quotes s as a Groovy single-quoted string.
+func groovyString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
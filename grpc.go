@@ -0,0 +1,124 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// ErrGrpcReflectionUnavailable is returned when the gRPC mock endpoint doesn't enable server reflection.
+// Use errors.Is to check it.
+var ErrGrpcReflectionUnavailable = errors.New("gRPC server reflection unavailable")
+
+// GrpcMockTarget get the host:port target of the exposed mock endpoint for GRPC Services, as accepted by grpc.NewClient.
+func (container *MicrocksContainer) GrpcMockTarget(ctx context.Context) (string, error) {
+	ip, err := container.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := container.MappedPort(ctx, DefaultGrpcPort)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(ip, port.Port()), nil
+}
+
+// GrpcMockConn creates a client connection to the exposed mock endpoint for GRPC Services. It uses insecure
+// credentials, unless dialOpts sets others. Close the connection when done.
+func (container *MicrocksContainer) GrpcMockConn(ctx context.Context, dialOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	target, err := container.GrpcMockTarget(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving gRPC mock endpoint: %w", err)
+	}
+
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, dialOpts...)
+	return grpc.NewClient(target, opts...)
+}
+
+// GrpcMockServices lists the fully qualified names of the mocked GRPC Services, e.g. "io.github.microcks.grpc.hello.v1.HelloService",
+// through server reflection. Use it to check that proto artifacts have been imported.
+// It returns an error wrapping ErrGrpcReflectionUnavailable if Microcks doesn't enable server reflection.
+func (container *MicrocksContainer) GrpcMockServices(ctx context.Context, dialOpts ...grpc.DialOption) ([]string, error) {
+	conn, err := container.GrpcMockConn(ctx, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	services, err := listServices(ctx, conn, reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName)
+	if status.Code(err) == codes.Unimplemented {
+		services, err = listServices(ctx, conn, reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName)
+	}
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("%w: %w", ErrGrpcReflectionUnavailable, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing gRPC services: %w", err)
+	}
+
+	// Don't report the reflection services themselves.
+	services = slices.DeleteFunc(services, func(service string) bool {
+		return strings.HasPrefix(service, "grpc.reflection.")
+	})
+	slices.Sort(services)
+	return services, nil
+}
+
+// listServices lists the services of a server through the server reflection method. v1 and the deprecated
+// v1alpha reflection messages being the same on the wire, v1 ones are used for both.
+func listServices(ctx context.Context, conn *grpc.ClientConn, method string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	desc := &grpc.StreamDesc{StreamName: "ServerReflectionInfo", ServerStreams: true, ClientStreams: true}
+	stream, err := conn.NewStream(ctx, desc, method)
+	if err != nil {
+		return nil, err
+	}
+	request := &reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{ListServices: "*"},
+	}
+	if err := stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+	response := &reflectionv1.ServerReflectionResponse{}
+	if err := stream.RecvMsg(response); err != nil {
+		return nil, err
+	}
+	if e := response.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	return services, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// newGrpcStubContainer returns a MicrocksContainer whose gRPC mock endpoint is served by a server
+// exposing the health service, after register has been called on it.
+func newGrpcStubContainer(t *testing.T, register func(s *grpc.Server)) *MicrocksContainer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return &MicrocksContainer{Container: &stubContainer{endpoint: &url.URL{Host: listener.Addr().String()}}}
+}
+
+func TestGrpcMockServices(t *testing.T) {
+	ctx := context.Background()
+
+	cases := map[string]func(s *grpc.Server){
+		"v1 and v1alpha": func(s *grpc.Server) { reflection.Register(s) },
+		"v1alpha only": func(s *grpc.Server) {
+			reflectionv1alpha.RegisterServerReflectionServer(s, reflection.NewServer(reflection.ServerOptions{Services: s}))
+		},
+	}
+	for name, register := range cases {
+		container := newGrpcStubContainer(t, register)
+		services, err := container.GrpcMockServices(ctx)
+		require.NoError(t, err, name)
+		require.Equal(t, []string{"grpc.health.v1.Health"}, services, name)
+	}
+
+	container := newGrpcStubContainer(t, func(*grpc.Server) {})
+	_, err := container.GrpcMockServices(ctx)
+	require.ErrorIs(t, err, ErrGrpcReflectionUnavailable)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package artifact holds the helpers shared by the packages rendering Microcks artifacts in Go.
+package artifact
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileNameSeparators matches what's replaced by dashes in artifact file names.
+var fileNameSeparators = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// FileName returns the name of an artifact of a service, identified by its name and version.
+// kind tells the artifact apart from the others of the service, e.g. "openapi" or "examples".
+func FileName(service string, version string, kind string) string {
+	slug := strings.Trim(fileNameSeparators.ReplaceAllString(strings.ToLower(service+"-"+version), "-"), "-")
+	return slug + "-" + kind + ".yaml"
+}
+
+// RenderYAML encodes an artifact as YAML, indented with two spaces.
+func RenderYAML(artifact any) ([]byte, error) {
+	var content bytes.Buffer
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(artifact); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+// JSONValue converts value to its JSON representation, so that it's rendered in YAML as encoding/json
+// would render it. Strings are kept as is.
+func JSONValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var converted any
+	err = json.Unmarshal(payload, &converted)
+	return converted, err
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package artifact_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"microcks.io/testcontainers-go/internal/artifact"
+)
+
+func TestFileName(t *testing.T) {
+	require.Equal(t, "api-pastries-0.0.1-examples.yaml", artifact.FileName("API Pastries", "0.0.1", "examples"))
+	require.Equal(t, "order-service-v2-openapi.yaml", artifact.FileName(" Order/Service ", "v2", "openapi"))
+}
+
+func TestJSONValue(t *testing.T) {
+	type pastry struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price,omitempty"`
+	}
+	value, err := artifact.JSONValue(pastry{Name: "Eclair"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "Eclair"}, value)
+
+	value, err = artifact.JSONValue("<pastry/>")
+	require.NoError(t, err)
+	require.Equal(t, "<pastry/>", value)
+
+	value, err = artifact.JSONValue(nil)
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	_, err = artifact.JSONValue(make(chan int))
+	require.Error(t, err)
+}
+
+func TestRenderYAML(t *testing.T) {
+	content, err := artifact.RenderYAML(map[string]any{"metadata": map[string]string{"name": "API Pastries"}})
+	require.NoError(t, err)
+	require.Equal(t, "metadata:\n  name: API Pastries\n", string(content))
+}
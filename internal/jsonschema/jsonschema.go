@@ -0,0 +1,145 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package jsonschema checks that JSON values hold the properties a JSON Schema, as found in
+// OpenAPI and AsyncAPI specifications, marks as required.
+//
+// Only required, properties, items, allOf and local $ref are followed. Other keywords, like type
+// or enum, are ignored: this catches missing fields, not a full schema validation.
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CheckRequired checks that value, as decoded by encoding/json, holds the properties schema requires.
+// document is the specification holding schema, used to resolve references. All the problems are reported
+// in the error, prefixed by the JSON path of the faulty value.
+func CheckRequired(document any, schema any, value any) error {
+	c := checker{document: document}
+	c.check("$", schema, value, 0)
+	return errors.Join(c.errs...)
+}
+
+// maxDepth protects against recursive references.
+const maxDepth = 64
+
+type checker struct {
+	document any
+	errs     []error
+}
+
+func (c *checker) fail(path string, format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (c *checker) check(path string, schema any, value any, depth int) {
+	if depth > maxDepth {
+		c.fail(path, "schema is too deep")
+		return
+	}
+
+	var s map[string]any
+	switch schema := schema.(type) {
+	case map[string]any:
+		s = schema
+	case nil, bool:
+		return
+	default:
+		c.fail(path, "schema is not an object with string keys")
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		resolved, err := resolve(c.document, ref)
+		if err != nil {
+			c.fail(path, "%v", err)
+			return
+		}
+		c.check(path, resolved, value, depth+1)
+		return
+	}
+
+	if allOf, ok := s["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			c.check(path, sub, value, depth+1)
+		}
+	}
+
+	object, isObject := value.(map[string]any)
+	if required, ok := s["required"].([]any); ok {
+		if !isObject {
+			c.fail(path, "expected an object holding the required properties")
+			return
+		}
+		for _, name := range required {
+			if _, ok := object[fmt.Sprint(name)]; !ok {
+				c.fail(path, "required property %q is missing", fmt.Sprint(name))
+			}
+		}
+	}
+
+	if isObject {
+		properties, _ := s["properties"].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(properties)) {
+			if v, ok := object[name]; ok {
+				c.check(path+"."+name, properties[name], v, depth+1)
+			}
+		}
+	}
+	if items, ok := value.([]any); ok {
+		for i, item := range items {
+			c.check(path+"["+strconv.Itoa(i)+"]", s["items"], item, depth+1)
+		}
+	}
+}
+
+// resolve resolves a local reference, a JSON pointer within document.
+func resolve(document any, ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("only local references are supported, got %q", ref)
+	}
+
+	current := document
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := current.(type) {
+		case map[string]any:
+			current, ok = node[token]
+		case []any:
+			index, err := strconv.Atoi(token)
+			ok = err == nil && index >= 0 && index < len(node)
+			if ok {
+				current = node[index]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot resolve reference %q", ref)
+		}
+	}
+	return current, nil
+}
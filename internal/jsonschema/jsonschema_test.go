@@ -0,0 +1,74 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"microcks.io/testcontainers-go/internal/jsonschema"
+)
+
+const document = `
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        owners:
+          type: array
+          items:
+            $ref: "#/components/schemas/Owner"
+    Owner:
+      allOf:
+        - required: [email]
+        - properties:
+            address:
+              required: [city]
+`
+
+func check(t *testing.T, payload string) error {
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(document), &doc))
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(payload), &value))
+	return jsonschema.CheckRequired(doc, map[string]any{"$ref": "#/components/schemas/Pet"}, value)
+}
+
+func TestCheckRequired(t *testing.T) {
+	require.NoError(t, check(t, `{"id": "not checked", "name": "Rusty"}`))
+	require.NoError(t, check(t, `{"id": 1, "name": "Rusty", "owners": [{"email": "l@b.io", "address": {"city": "Paris"}}]}`))
+
+	err := check(t, `{"id": 3, "owners": [{}, {"email": "l@b.io", "address": {}}, "Yacine"]}`)
+	require.EqualError(t, err, `$: required property "name" is missing
+$.owners[0]: required property "email" is missing
+$.owners[1].address: required property "city" is missing
+$.owners[2]: expected an object holding the required properties`)
+
+	err = check(t, `[]`)
+	require.EqualError(t, err, "$: expected an object holding the required properties")
+
+	err = jsonschema.CheckRequired(nil, map[string]any{"$ref": "#/components/schemas/Missing"}, "value")
+	require.EqualError(t, err, `$: cannot resolve reference "#/components/schemas/Missing"`)
+
+	err = jsonschema.CheckRequired(nil, map[any]any{1: "one"}, "value")
+	require.EqualError(t, err, "$: schema is not an object with string keys")
+}
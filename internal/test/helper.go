@@ -36,6 +36,7 @@ import (
 	"microcks.io/go-client"
 	microcks "microcks.io/testcontainers-go"
 	"microcks.io/testcontainers-go/ensemble/async"
+	"microcks.io/testcontainers-go/microckstest"
 )
 
 // ConfigRetrieval tests the configuration.
@@ -99,6 +100,34 @@ func MockEndpoints(t *testing.T, ctx context.Context, microcksContainer *microck
 	require.Equal(t, "grpc://"+ip+":"+port.Port(), baseGrpcUrl)
 }
 
+// ServiceCatalog tests the services imported in Microcks.
+func ServiceCatalog(t *testing.T, ctx context.Context, microcksContainer *microcks.MicrocksContainer) {
+	exists, err := microcksContainer.ServiceExists(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = microcksContainer.ServiceExists(ctx, "API Pastries", "9.9.9")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	services, err := microcksContainer.ListServices(ctx, microcks.ServiceFilter{Type: "REST"})
+	require.NoError(t, err)
+	require.NotEmpty(t, services)
+
+	service, err := microcksContainer.GetService(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "REST", service.Type)
+	require.Len(t, service.Operations, 3)
+
+	operation, ok := service.Operation("GET /pastries/{name}")
+	require.True(t, ok)
+	require.Equal(t, "GET", operation.Method)
+	require.NotEmpty(t, operation.Dispatcher)
+
+	_, err = microcksContainer.GetService(ctx, "API Pastries", "9.9.9")
+	require.ErrorIs(t, err, microcks.ErrServiceNotFound)
+}
+
 // MicrocksMockingFunctionality tests the Microcks mocking functionality.
 func MicrocksMockingFunctionality(t *testing.T, ctx context.Context, microcksContainer *microcks.MicrocksContainer) {
 	baseApiUrl, err := microcksContainer.RestMockEndpoint(ctx, "API Pastries", "0.0.1")
@@ -145,10 +174,42 @@ func MicrocksMockingFunctionality(t *testing.T, ctx context.Context, microcksCon
 
 	require.Equal(t, "Eclair Chocolat", pastry["name"])
 
-	// Check it has been called a second time.
-	callCount, err = microcksContainer.ServiceInvocationsCount(ctx, "API Pastries", "0.0.1")
+	// Check it has been called a second time, once Microcks has processed the invocation.
+	callCount, err = microcksContainer.WaitForInvocations(ctx, "API Pastries", "0.0.1", 2, 5*time.Second)
 	require.NoError(t, err)
 	require.Equal(t, 2, callCount)
+
+	callCount, err = microcksContainer.WaitForOperationInvocations(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}", 2, 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 2, callCount)
+
+	// Check invocations at the operation level.
+	operationCount, err := microcksContainer.OperationInvocationsCount(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}")
+	require.NoError(t, err)
+	require.Equal(t, 2, operationCount)
+
+	require.NoError(t, microcksContainer.VerifyOperation("API Pastries", "0.0.1", "GET /pastries/{name}").Times(ctx, 2))
+	require.NoError(t, microcksContainer.VerifyOperation("API Pastries", "0.0.1", "GET /pastries").Never(ctx))
+
+	err = microcksContainer.VerifyOperation("API Pastries", "0.0.1", "GET /pastries/{name}").AtLeast(ctx, 3)
+	var verificationErr *microcks.VerificationError
+	require.ErrorAs(t, err, &verificationErr)
+	require.Equal(t, 2, verificationErr.Observed["GET /pastries/{name}"])
+
+	// Check the date is honoured: nothing has been invoked yesterday.
+	called, err = microcksContainer.VerifyAtDate(ctx, "API Pastries", "0.0.1", time.Now().AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.False(t, called)
+
+	// Check invocations are found within the last minutes.
+	now := time.Now()
+	buckets, err := microcksContainer.ServiceInvocationsByMinute(ctx, "API Pastries", "0.0.1", now.Add(-5*time.Minute), now.Add(time.Minute))
+	require.NoError(t, err)
+	total := 0
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+	require.Equal(t, 2, total)
 }
 
 // MicrocksAsyncMockingFunctionality tests the Microcks async mocking functionality.
@@ -286,6 +347,7 @@ func AssertBadImplementation(t *testing.T, ctx context.Context, microcksContaine
 
 	t0 := (*testResult.TestCaseResults)[0].TestStepResults
 	require.True(t, strings.Contains(*(*t0)[0].Message, "required property 'status' not found"))
+	microckstest.AssertOperationFails(t, testResult, "GET /pastries", "required property 'status' not found")
 
 	// Retrieve messages for the failing test case.
 	messages, err := microcksContainer.MessagesForTestCase(ctx, testResult, "GET /pastries")
@@ -316,6 +378,7 @@ func AssertGoodImplementation(t *testing.T, ctx context.Context, microcksContain
 
 	require.True(t, testResult.Success)
 	require.Equal(t, "http://good-impl:3002", testResult.TestedEndpoint)
+	microckstest.RequireConformance(t, testResult, microckstest.WithMessages(ctx, microcksContainer))
 
 	require.Equal(t, 3, len(*testResult.TestCaseResults))
 	for _, r := range *testResult.TestCaseResults {
@@ -0,0 +1,253 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultInvocationsSettleTimeout is how long verifications wait, by default, for asynchronously processed
+// invocations to show up before reporting missing ones.
+const DefaultInvocationsSettleTimeout = 2 * time.Second
+
+// InvocationsBucket holds the invocations' count of a mock within a time bucket.
+type InvocationsBucket struct {
+	// Start is the beginning of the bucket, in the invocations timezone.
+	Start time.Time
+	// Count is the number of invocations within the bucket.
+	Count int
+}
+
+// dailyInvocationStatistic is the Microcks representation of a service invocations during a day.
+// Hourly counts are indexed by hour of day and minute counts by minute of day.
+type dailyInvocationStatistic struct {
+	Day         string                                  `json:"day"`
+	DailyCount  int                                     `json:"dailyCount"`
+	HourlyCount map[string]int                          `json:"hourlyCount"`
+	MinuteCount map[string]int                          `json:"minuteCount"`
+	Operations  map[string]operationInvocationStatistic `json:"operations"`
+}
+
+// operationInvocationStatistic is the Microcks representation of an operation invocations during a day.
+type operationInvocationStatistic struct {
+	DailyCount  int            `json:"dailyCount"`
+	HourlyCount map[string]int `json:"hourlyCount"`
+}
+
+// operationCounts returns the daily invocations' count of each operation. It fails if the service has been
+// invoked while Microcks didn't report any per-operation statistics.
+func (s *dailyInvocationStatistic) operationCounts() (map[string]int, error) {
+	if s.Operations == nil && s.DailyCount > 0 {
+		return nil, errors.New("microcks doesn't report per-operation invocations statistics")
+	}
+	counts := make(map[string]int, len(s.Operations))
+	for operation, stats := range s.Operations {
+		counts[operation] = stats.DailyCount
+	}
+	return counts, nil
+}
+
+// WithInvocationsTimezone sets the timezone Microcks uses for computing invocations statistics.
+// It defaults to UTC, which is the Microcks container timezone unless you change it.
+func WithInvocationsTimezone(location *time.Location) Option {
+	return func(o *options) error {
+		o.invocationsLocation = location
+		return nil
+	}
+}
+
+// WithInvocationsSettleTimeout sets how long Verify and VerifyOperation wait for asynchronously processed
+// invocations to show up before reporting missing ones. It defaults to DefaultInvocationsSettleTimeout;
+// zero checks the current counts only. Verifications expecting no invocations, like Never, do not wait
+// whatever the timeout: they check the current counts.
+func WithInvocationsSettleTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout < 0 {
+			return fmt.Errorf("invocations settle timeout %s is negative", timeout)
+		}
+		o.invocationsSettleTimeout = &timeout
+		return nil
+	}
+}
+
+// ServiceInvocationsByHour gets the hourly invocations' counts for a given service, identified by its name
+// and version, for every hour overlapping the [from, to) window. Hours are those of the invocations timezone.
+func (container *MicrocksContainer) ServiceInvocationsByHour(ctx context.Context, serviceName string, serviceVersion string, from time.Time, to time.Time) ([]InvocationsBucket, error) {
+	return container.serviceInvocationsBuckets(ctx, serviceName, serviceVersion, from, to, time.Hour)
+}
+
+// ServiceInvocationsByMinute gets the invocations' counts per minute for a given service, identified by its name
+// and version, for every minute overlapping the [from, to) window.
+func (container *MicrocksContainer) ServiceInvocationsByMinute(ctx context.Context, serviceName string, serviceVersion string, from time.Time, to time.Time) ([]InvocationsBucket, error) {
+	return container.serviceInvocationsBuckets(ctx, serviceName, serviceVersion, from, to, time.Minute)
+}
+
+func (container *MicrocksContainer) serviceInvocationsBuckets(ctx context.Context, serviceName string, serviceVersion string, from time.Time, to time.Time, granularity time.Duration) ([]InvocationsBucket, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid invocations window: %s is not before %s", from, to)
+	}
+
+	// Statistics are retrieved once per day of the window.
+	days := make(map[string]*dailyInvocationStatistic)
+
+	var buckets []InvocationsBucket
+	for start := container.bucketStart(from, granularity); start.Before(to); start = start.Add(granularity) {
+		day := container.formatDay(start)
+		stats, ok := days[day]
+		if !ok {
+			var err error
+			if stats, err = container.serviceInvocationStats(ctx, serviceName, serviceVersion, start); err != nil {
+				return nil, err
+			}
+			days[day] = stats
+		}
+
+		var count int
+		if granularity == time.Hour {
+			count = stats.HourlyCount[strconv.Itoa(start.Hour())]
+		} else {
+			count = stats.MinuteCount[strconv.Itoa(start.Hour()*60+start.Minute())]
+		}
+		buckets = append(buckets, InvocationsBucket{Start: start, Count: count})
+	}
+	return buckets, nil
+}
+
+// OperationInvocationsCount gets the invocations' count of an operation of a given service, identified by its name
+// and version, for the current date. operation is the operation name, e.g. "GET /pastries/{name}".
+func (container *MicrocksContainer) OperationInvocationsCount(ctx context.Context, serviceName string, serviceVersion string, operation string) (int, error) {
+	return container.OperationInvocationsCountAtDate(ctx, serviceName, serviceVersion, operation, time.Now())
+}
+
+// OperationInvocationsCountAtDate gets the invocations' count of an operation of a given service, identified by its name
+// and version, for the given invocations' date.
+func (container *MicrocksContainer) OperationInvocationsCountAtDate(ctx context.Context, serviceName string, serviceVersion string, operation string, date time.Time) (int, error) {
+	counts, err := container.operationInvocationsCounts(ctx, serviceName, serviceVersion, date)
+	if err != nil {
+		return 0, err
+	}
+	return counts[operation], nil
+}
+
+// operationInvocationsCounts gets the invocations' count of every invoked operation of a service for the given date.
+func (container *MicrocksContainer) operationInvocationsCounts(ctx context.Context, serviceName string, serviceVersion string, date time.Time) (map[string]int, error) {
+	stats, err := container.serviceInvocationStats(ctx, serviceName, serviceVersion, date)
+	if err != nil {
+		return nil, err
+	}
+	return stats.operationCounts()
+}
+
+// WaitForInvocations waits until a given service, identified by its name and version, has been invoked
+// at least atLeast times for the current date. As Microcks processes metrics asynchronously, invocations
+// don't show up immediately: statistics are polled with the container backoff (see WithPollingBackoff) until they do, ctx is done or
+// timeout expires. The last observed count is returned, along with an error wrapping
+// context.DeadlineExceeded if the expected count didn't show up in time.
+func (container *MicrocksContainer) WaitForInvocations(ctx context.Context, serviceName string, serviceVersion string, atLeast int, timeout time.Duration) (int, error) {
+	return waitForCount(ctx, container.backoff(), atLeast, timeout, func(ctx context.Context) (int, error) {
+		stats, err := container.serviceInvocationStats(ctx, serviceName, serviceVersion, time.Now())
+		if err != nil {
+			return 0, err
+		}
+		return stats.DailyCount, nil
+	})
+}
+
+// WaitForOperationInvocations is the same as WaitForInvocations, for an operation of the service.
+func (container *MicrocksContainer) WaitForOperationInvocations(ctx context.Context, serviceName string, serviceVersion string, operation string, atLeast int, timeout time.Duration) (int, error) {
+	return waitForCount(ctx, container.backoff(), atLeast, timeout, func(ctx context.Context) (int, error) {
+		return container.OperationInvocationsCountAtDate(ctx, serviceName, serviceVersion, operation, time.Now())
+	})
+}
+
+// waitForCount polls count until it reaches atLeast or timeout expires, and returns the last observed count.
+func waitForCount(ctx context.Context, backoff Backoff, atLeast int, timeout time.Duration, count func(ctx context.Context) (int, error)) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	observed := 0
+	err := poll(ctx, backoff, func(ctx context.Context) (bool, error) {
+		current, err := count(ctx)
+		if err != nil {
+			return false, err
+		}
+		observed = current
+		return observed >= atLeast, nil
+	})
+	if err != nil {
+		return observed, fmt.Errorf("waiting for at least %d invocations, last observed %d: %w", atLeast, observed, err)
+	}
+	return observed, nil
+}
+
+// settledCount waits for count to reach atLeast within the invocations settle timeout and returns the last observed count.
+// Contrary to waitForCount, not reaching atLeast in time is not an error.
+func (container *MicrocksContainer) settledCount(ctx context.Context, atLeast int, count func(ctx context.Context) (int, error)) (int, error) {
+	timeout := container.invocationsSettleTimeout()
+	if timeout == 0 {
+		return count(ctx)
+	}
+
+	observed, err := waitForCount(ctx, container.backoff(), atLeast, timeout, count)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return observed, nil
+	}
+	return observed, err
+}
+
+// invocationsSettleTimeout returns how long verifications wait for invocations to show up.
+func (container *MicrocksContainer) invocationsSettleTimeout() time.Duration {
+	if container.settleTimeout != nil {
+		return *container.settleTimeout
+	}
+	return DefaultInvocationsSettleTimeout
+}
+
+// serviceInvocationStats retrieves the invocations statistics of a service for the day of date.
+func (container *MicrocksContainer) serviceInvocationStats(ctx context.Context, serviceName string, serviceVersion string, date time.Time) (*dailyInvocationStatistic, error) {
+	stats := &dailyInvocationStatistic{}
+	path := "/metrics/invocations/" + url.PathEscape(serviceName) + "/" + url.PathEscape(serviceVersion)
+	query := url.Values{"day": {container.formatDay(date)}}
+
+	if _, err := container.callAPI(ctx, OperationGetInvocationStats, http.MethodGet, path, query, nil, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// bucketStart returns the beginning of the hour or minute holding t, in the invocations timezone.
+func (container *MicrocksContainer) bucketStart(t time.Time, granularity time.Duration) time.Time {
+	t = t.In(container.invocationsTimezone())
+	minute := t.Minute()
+	if granularity == time.Hour {
+		minute = 0
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, t.Location())
+}
+
+// invocationsTimezone returns the timezone Microcks uses for computing invocations statistics.
+func (container *MicrocksContainer) invocationsTimezone() *time.Location {
+	if container.invocationsLocation != nil {
+		return container.invocationsLocation
+	}
+	return time.UTC
+}
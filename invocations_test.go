@@ -0,0 +1,129 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// invocationStats serves the invocations statistics returned by stats, called with the number of the request.
+func invocationStats(t *testing.T, stats func(call int64) dailyInvocationStatistic) (http.Handler, *atomic.Int64) {
+	calls := &atomic.Int64{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/metrics/invocations/API Pastries/0.0.1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats(calls.Add(1))); err != nil {
+			t.Errorf("cannot encode invocations stats: %v", err)
+			return
+		}
+	}), calls
+}
+
+func TestWaitForInvocations(t *testing.T) {
+	handler, calls := invocationStats(t, func(call int64) dailyInvocationStatistic {
+		// Invocations show up on the third poll.
+		if call < 3 {
+			return dailyInvocationStatistic{}
+		}
+		return dailyInvocationStatistic{DailyCount: 2}
+	})
+	container := newStubContainer(t, handler)
+
+	count, err := container.WaitForInvocations(context.Background(), "API Pastries", "0.0.1", 2, 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestWaitForInvocationsTimeout(t *testing.T) {
+	handler, _ := invocationStats(t, func(int64) dailyInvocationStatistic {
+		return dailyInvocationStatistic{DailyCount: 1}
+	})
+	container := newStubContainer(t, handler)
+
+	count, err := container.WaitForInvocations(context.Background(), "API Pastries", "0.0.1", 2, 50*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, count)
+}
+
+func TestVerifySettleTimeout(t *testing.T) {
+	handler, calls := invocationStats(t, func(int64) dailyInvocationStatistic {
+		return dailyInvocationStatistic{}
+	})
+	container := newStubContainer(t, handler)
+
+	// A missing invocation is reported once the settle timeout expires, not before.
+	settleTimeout := 100 * time.Millisecond
+	container.settleTimeout = &settleTimeout
+	start := time.Now()
+	invoked, err := container.Verify(context.Background(), "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	require.False(t, invoked)
+	require.GreaterOrEqual(t, time.Since(start), settleTimeout)
+	require.Less(t, time.Since(start), DefaultInvocationsSettleTimeout)
+	require.Greater(t, calls.Load(), int64(1))
+
+	// Without settle timeout, current counts are checked once.
+	handler, calls = invocationStats(t, func(int64) dailyInvocationStatistic {
+		return dailyInvocationStatistic{}
+	})
+	container = newStubContainer(t, handler)
+	settleTimeout = 0
+	container.settleTimeout = &settleTimeout
+	invoked, err = container.Verify(context.Background(), "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	require.False(t, invoked)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestVerifyOperation(t *testing.T) {
+	handler, _ := invocationStats(t, func(call int64) dailyInvocationStatistic {
+		return dailyInvocationStatistic{DailyCount: 2, Operations: map[string]operationInvocationStatistic{
+			"GET /pastries/{name}": {DailyCount: 2},
+		}}
+	})
+	container := newStubContainer(t, handler)
+	settleTimeout := 20 * time.Millisecond
+	container.settleTimeout = &settleTimeout
+
+	ctx := context.Background()
+	require.NoError(t, container.VerifyOperation("API Pastries", "0.0.1", "GET /pastries/{name}").Times(ctx, 2))
+	require.NoError(t, container.VerifyOperation("API Pastries", "0.0.1", "GET /pastries").Never(ctx))
+
+	err := container.VerifyOperation("API Pastries", "0.0.1", "GET /pastries/{name}").AtLeast(ctx, 3)
+	var verificationError *VerificationError
+	require.True(t, errors.As(err, &verificationError))
+	require.Equal(t, map[string]int{"GET /pastries/{name}": 2}, verificationError.Observed)
+}
+
+func TestWithInvocationsSettleTimeout(t *testing.T) {
+	settings := options{}
+	require.NoError(t, WithInvocationsSettleTimeout(0)(&settings))
+	require.Equal(t, time.Duration(0), *settings.invocationsSettleTimeout)
+	require.Error(t, WithInvocationsSettleTimeout(-time.Second)(&settings))
+}
@@ -18,15 +18,19 @@ package microcks
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/testcontainers/testcontainers-go"
@@ -50,6 +54,60 @@ const (
 // MicrocksContainer represents the Microcks container type used in the module.
 type MicrocksContainer struct {
 	testcontainers.Container
+
+	httpClient          *http.Client
+	invocationsLocation *time.Location
+	pollingBackoff      *Backoff
+	settleTimeout       *time.Duration
+	startupSecrets      []string
+
+	apiClientMu sync.Mutex
+	apiClient   *client.ClientWithResponses
+}
+
+// Option is a functional option that configures the MicrocksContainer itself
+// rather than the underlying container request.
+type Option func(*options) error
+
+// Customize implements testcontainers.ContainerCustomizer. Within Run, it applies the option to the
+// container being run. Elsewhere, e.g. with testcontainers.GenericContainer, imports are run once the
+// container is ready, and options only configuring the MicrocksContainer are rejected.
+func (o Option) Customize(req *testcontainers.GenericContainerRequest) error {
+	if settings, ok := runningRequests.Load(req); ok {
+		return o(settings.(*options))
+	}
+
+	settings := &options{}
+	if err := o(settings); err != nil {
+		return err
+	}
+	if settings.plan.empty() {
+		return errors.New("option only applies to containers created with microcks.Run")
+	}
+	req.HostAccessPorts = append(req.HostAccessPorts, settings.hostAccessPorts...)
+	req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+		PostReadies: []testcontainers.ContainerHook{
+			func(ctx context.Context, container testcontainers.Container) error {
+				return settings.plan.execute(ctx, &MicrocksContainer{Container: container, startupSecrets: settings.startupSecrets})
+			},
+		},
+	})
+	return nil
+}
+
+// runningRequests holds the settings of the requests being customized by Run, so that options
+// returning testcontainers.CustomizeRequestOption still take part in the import plan.
+var runningRequests sync.Map
+
+// options holds the settings gathered from Option values.
+type options struct {
+	httpClient               *http.Client
+	invocationsLocation      *time.Location
+	pollingBackoff           *Backoff
+	invocationsSettleTimeout *time.Duration
+	startupSecrets           []string
+	hostAccessPorts          []int
+	plan                     importPlan
 }
 
 // Deprecated: use Run instead
@@ -59,6 +117,11 @@ func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomize
 }
 
 // Run creates an instance of the MicrocksContainer type.
+// Once the container is ready, content provided through options is imported in this order, whatever
+// the options order: secrets, snapshots, main artifacts, secondary artifacts, main remote artifacts,
+// secondary remote artifacts, snapshot fixtures and finally webhook registrations. Items of a same kind
+// are imported concurrently, and all failures are reported together.
+// If the container has been created but failed to get ready or to import content, it's returned along with the error.
 func Run(ctx context.Context, image string, opts ...testcontainers.ContainerCustomizer) (*MicrocksContainer, error) {
 	req := testcontainers.ContainerRequest{
 		Image:        image,
@@ -70,16 +133,81 @@ func Run(ctx context.Context, image string, opts ...testcontainers.ContainerCust
 		Started:          true,
 	}
 
+	settings := options{}
+	runningRequests.Store(&genericContainerReq, &settings)
+	defer runningRequests.Delete(&genericContainerReq)
 	for _, opt := range opts {
-		opt.Customize(&genericContainerReq)
+		if err := opt.Customize(&genericContainerReq); err != nil {
+			return nil, err
+		}
+	}
+	genericContainerReq.HostAccessPorts = append(genericContainerReq.HostAccessPorts, settings.hostAccessPorts...)
+
+	microcksContainer := &MicrocksContainer{
+		httpClient:          settings.httpClient,
+		invocationsLocation: settings.invocationsLocation,
+		pollingBackoff:      settings.pollingBackoff,
+		settleTimeout:       settings.invocationsSettleTimeout,
+		startupSecrets:      settings.startupSecrets,
+	}
+	if !settings.plan.empty() {
+		// Secrets, snapshots, artifacts and webhooks are imported by a single hook that
+		// follows the plan order, whatever the options order.
+		genericContainerReq.LifecycleHooks = append(genericContainerReq.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+			PostReadies: []testcontainers.ContainerHook{
+				func(ctx context.Context, container testcontainers.Container) error {
+					microcksContainer.Container = container
+					return settings.plan.execute(ctx, microcksContainer)
+				},
+			},
+		})
 	}
 
 	container, err := testcontainers.GenericContainer(ctx, genericContainerReq)
 	if err != nil {
+		if container != nil {
+			// Container has been created but is not ready or imports failed: return it so that it can be terminated.
+			microcksContainer.Container = container
+			return microcksContainer, err
+		}
 		return nil, err
 	}
 
-	return &MicrocksContainer{Container: container}, nil
+	microcksContainer.Container = container
+	return microcksContainer, nil
+}
+
+// WithHTTPClient sets the HTTP client used to call the Microcks API, at startup
+// and afterwards. Useful to set timeouts, tracing or request logging.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) error {
+		o.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithTransport sets the transport of the HTTP client used to call the Microcks API.
+// It can be combined with WithHTTPClient, in which case the given client is copied
+// and its transport replaced.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) error {
+		httpClient := &http.Client{}
+		if o.httpClient != nil {
+			*httpClient = *o.httpClient
+		}
+		httpClient.Transport = transport
+		o.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithPollingBackoff sets the backoff used when polling Microcks for test results and invocations.
+// It defaults to DefaultBackoff.
+func WithPollingBackoff(backoff Backoff) Option {
+	return func(o *options) error {
+		o.pollingBackoff = &backoff
+		return nil
+	}
 }
 
 // WithDebugLogLevel sets Microcks log level to DEBUG.
@@ -104,54 +232,63 @@ func WithSecondaryArtifact(artifactFilePath string) testcontainers.CustomizeRequ
 
 // WithSnapshot provides paths to local repository snapshots that will be imported within the Microcks container.
 func WithSnapshot(snapshotFilePath string) testcontainers.CustomizeRequestOption {
-	return func(req *testcontainers.GenericContainerRequest) error {
-		hooks := testcontainers.ContainerLifecycleHooks{
-			PostReadies: []testcontainers.ContainerHook{
-				importSnapshotHook(snapshotFilePath),
-			},
-		}
-		req.LifecycleHooks = append(req.LifecycleHooks, hooks)
+	return Option(func(o *options) error {
+		o.plan.add(phaseSnapshots, "snapshot "+snapshotFilePath, importSnapshotAction(snapshotFilePath))
 		return nil
-	}
+	}).Customize
 }
 
 // WithMainRemoteArtifact provides urls of remote artifacts that will be imported as primary or main ones within the Microcks container.
 func WithMainRemoteArtifact(remoteArtifactUrl string, secretName ...string) testcontainers.CustomizeRequestOption {
-	return func(req *testcontainers.GenericContainerRequest) error {
-		hooks := testcontainers.ContainerLifecycleHooks{
-			PostReadies: []testcontainers.ContainerHook{
-				downloadArtifactHook(remoteArtifactUrl, true, secretName...),
-			},
-		}
-		req.LifecycleHooks = append(req.LifecycleHooks, hooks)
+	return Option(func(o *options) error {
+		o.plan.add(phaseMainRemoteArtifacts, "remote artifact "+remoteArtifactUrl, downloadArtifactAction(remoteArtifactUrl, true, secretName...))
 		return nil
-	}
+	}).Customize
 }
 
 // WithSecondaryRemoteArtifact provides urls of remote artifacts that will be imported as secondary ones within the Microcks container.
 func WithSecondaryRemoteArtifact(remoteArtifactUrl string, secretName ...string) testcontainers.CustomizeRequestOption {
-	return func(req *testcontainers.GenericContainerRequest) error {
-		hooks := testcontainers.ContainerLifecycleHooks{
-			PostReadies: []testcontainers.ContainerHook{
-				downloadArtifactHook(remoteArtifactUrl, false, secretName...),
-			},
-		}
-		req.LifecycleHooks = append(req.LifecycleHooks, hooks)
+	return Option(func(o *options) error {
+		o.plan.add(phaseSecondaryRemoteArtifacts, "remote artifact "+remoteArtifactUrl, downloadArtifactAction(remoteArtifactUrl, false, secretName...))
 		return nil
-	}
+	}).Customize
 }
 
 // WithArtifact provides paths to artifacts that will be imported within the Microcks container.
 // Once it will be started and healthy.
 func WithArtifact(artifactFilePath string, main bool) testcontainers.CustomizeRequestOption {
-	return func(req *testcontainers.GenericContainerRequest) error {
-		hooks := testcontainers.ContainerLifecycleHooks{
-			PostReadies: []testcontainers.ContainerHook{
-				importArtifactHook(artifactFilePath, main),
-			},
-		}
-		req.LifecycleHooks = append(req.LifecycleHooks, hooks)
+	return Option(func(o *options) error {
+		o.plan.add(artifactPhase(main), "artifact "+artifactFilePath, importArtifactAction(artifactFilePath, main))
+		return nil
+	}).Customize
+}
+
+// WithArtifactsFS provides artifacts from a file system - typically an embed.FS - that will be imported
+// as main ones within the Microcks container. Patterns follow fs.Glob syntax and must match at least one file.
+func WithArtifactsFS(fsys fs.FS, patterns ...string) Option {
+	return withArtifactsFS(fsys, true, patterns...)
+}
+
+// WithSecondaryArtifactsFS provides artifacts from a file system - typically an embed.FS - that will be imported
+// as secondary ones within the Microcks container. Patterns follow fs.Glob syntax and must match at least one file.
+func WithSecondaryArtifactsFS(fsys fs.FS, patterns ...string) Option {
+	return withArtifactsFS(fsys, false, patterns...)
+}
 
+func withArtifactsFS(fsys fs.FS, main bool, patterns ...string) Option {
+	return func(o *options) error {
+		for _, pattern := range patterns {
+			matches, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return fmt.Errorf("invalid artifact pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("no artifact matching pattern %q", pattern)
+			}
+			for _, name := range matches {
+				o.plan.add(artifactPhase(main), "artifact "+name, importArtifactFSAction(fsys, name, main))
+			}
+		}
 		return nil
 	}
 }
@@ -201,16 +338,46 @@ func WithHostAccessPorts(hostAccessPorts []int) testcontainers.CustomizeRequestO
 
 // WithSecret allows to add a new secret.
 func WithSecret(s client.Secret) testcontainers.CustomizeRequestOption {
-	return func(req *testcontainers.GenericContainerRequest) error {
-		hooks := testcontainers.ContainerLifecycleHooks{
-			PostReadies: []testcontainers.ContainerHook{
-				createSecretHook(s),
-			},
-		}
-		req.LifecycleHooks = append(req.LifecycleHooks, hooks)
-
+	return Option(func(o *options) error {
+		o.plan.add(phaseSecrets, "secret "+s.Name, createSecretAction(s))
+		o.startupSecrets = append(o.startupSecrets, s.Name)
 		return nil
+	}).Customize
+}
+
+// Client returns the Microcks API client bound to this container. It's built on
+// first use and then shared by all the container methods.
+// Use it to call Microcks APIs this module doesn't wrap yet.
+func (container *MicrocksContainer) Client(ctx context.Context) (*client.ClientWithResponses, error) {
+	container.apiClientMu.Lock()
+	defer container.apiClientMu.Unlock()
+
+	if container.apiClient != nil {
+		return container.apiClient, nil
+	}
+
+	// Retrieve API endpoint.
+	httpEndpoint, err := container.HttpEndpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
 	}
+
+	// Create Microcks client.
+	c, err := client.NewClientWithResponses(httpEndpoint+"/api", client.WithHTTPClient(container.apiHTTPClient()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating Microcks client: %w", err)
+	}
+
+	container.apiClient = c
+	return c, nil
+}
+
+// apiHTTPClient returns the HTTP client to use for calling Microcks APIs.
+func (container *MicrocksContainer) apiHTTPClient() *http.Client {
+	if container.httpClient != nil {
+		return container.httpClient
+	}
+	return http.DefaultClient
 }
 
 // HttpEndpoint allows retrieving the Http endpoint where Microcks can be accessed.
@@ -305,21 +472,18 @@ func (container *MicrocksContainer) GraphQLMockEndpointPath(ctx context.Context,
 }
 
 // GrpcMockEndpoint get the exposed mock endpoint for a GRPC Service.
+// Use GrpcMockTarget or GrpcMockConn with grpc.NewClient, that doesn't accept the grpc scheme.
 func (container *MicrocksContainer) GrpcMockEndpoint(ctx context.Context) (string, error) {
-	ip, err := container.Host(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	port, err := container.MappedPort(ctx, DefaultGrpcPort)
+	target, err := container.GrpcMockTarget(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("grpc://%s:%s", ip, port.Port()), nil
+	return "grpc://" + target, nil
 }
 
 // ImportAsMainArtifact imports an artifact as a primary or main one within the Microcks container.
+// It returns an *APIError if Microcks rejects the artifact, and a *ConnectionError if Microcks cannot be reached.
 func (container *MicrocksContainer) ImportAsMainArtifact(ctx context.Context, artifactFilePath string) (int, error) {
 	return container.importArtifact(ctx, artifactFilePath, true)
 }
@@ -329,6 +493,20 @@ func (container *MicrocksContainer) ImportAsSecondaryArtifact(ctx context.Contex
 	return container.importArtifact(ctx, artifactFilePath, false)
 }
 
+// ImportArtifactFromReader imports an artifact read from r within the Microcks container, as a main
+// or secondary one. name is the artifact file name: Microcks may rely on its extension to detect the
+// artifact type. Content is streamed to Microcks and never fully held in memory.
+func (container *MicrocksContainer) ImportArtifactFromReader(ctx context.Context, name string, r io.Reader, main bool) (int, error) {
+	return container.uploadArtifact(ctx, name, name, r, main)
+}
+
+// ImportArtifactFromBytes imports an in-memory artifact within the Microcks container, as a main
+// or secondary one. name is the artifact file name: Microcks may rely on its extension to detect the
+// artifact type.
+func (container *MicrocksContainer) ImportArtifactFromBytes(ctx context.Context, name string, content []byte, main bool) (int, error) {
+	return container.uploadArtifact(ctx, name, name, bytes.NewReader(content), main)
+}
+
 // ImportSnapshot imports a repository snapshot within the Microcks container.
 func (container *MicrocksContainer) ImportSnapshot(ctx context.Context, snapshotFilePath string) (int, error) {
 	return container.importSnapshot(ctx, snapshotFilePath)
@@ -344,55 +522,61 @@ func (container *MicrocksContainer) DownloadAsSecondaryArtifact(ctx context.Cont
 	return container.downloadArtifact(ctx, remoteArtifactUrl, false, secretName...)
 }
 
-// TestEndpoint launches a conformance test on an endpoint.
+// TestEndpoint launches a conformance test on an endpoint and polls Microcks until the test is done.
+// It returns an *APIError if Microcks refuses to launch the test or to return its result,
+// and a *ConnectionError if Microcks cannot be reached.
+// If the test is still in progress once its timeout (plus a one second grace period) has elapsed
+// or ctx is done, the last known partial TestResult is returned along with a *TestTimeoutError.
 func (container *MicrocksContainer) TestEndpoint(ctx context.Context, testRequest *client.TestRequest) (*client.TestResult, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
+	if testRequest == nil {
+		return nil, errors.New("test request is nil")
 	}
 
-	// Create Microcks client.
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
+	c, err := container.Client(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating Microcks client: %w", err)
+		return nil, &ConnectionError{Operation: OperationCreateTest, Err: err}
 	}
 
 	testResult, err := c.CreateTestWithResponse(ctx, *testRequest)
 	if err != nil {
-		return nil, fmt.Errorf("error creating test with response: %w", err)
+		return nil, &ConnectionError{Operation: OperationCreateTest, Err: err}
+	}
+	if testResult.StatusCode() != http.StatusCreated || testResult.JSON201 == nil {
+		return nil, &APIError{
+			Operation:  OperationCreateTest,
+			StatusCode: testResult.StatusCode(),
+			Body:       string(testResult.Body),
+		}
 	}
 
-	if testResult.HTTPResponse.StatusCode == 201 {
-		// Retrieve Id and start polling for final result.
-		var testResultId string = testResult.JSON201.Id
-
-		// Wait an initial delay to avoid inefficient poll.
-		time.Sleep(100 * time.Millisecond)
-
-		// Compute future time that is the end of waiting time frame + extra 1000 to avoid race condition.
-		future := nowInMilliseconds() + int64(testRequest.Timeout) + 1000
-		for nowInMilliseconds() < future {
-			testResultResponse, err := c.GetTestResultWithResponse(ctx, testResultId)
-			if err != nil {
-				return nil, fmt.Errorf("error getting test result with response: %w", err)
-			}
-
-			//fmt.Println(string(testResultResponse.Body[:]))
+	// Poll until the end of test timeout + extra 1000 ms to avoid race condition.
+	timeout := time.Duration(testRequest.Timeout)*time.Millisecond + time.Second
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-			// If still in progress, then wait again.
-			if testResultResponse.JSON200.InProgress {
-				time.Sleep(200 * time.Millisecond)
-			} else {
-				break
+	var lastResult *client.TestResult
+	err = poll(pollCtx, container.backoff(), func(ctx context.Context) (bool, error) {
+		response, err := c.GetTestResultWithResponse(ctx, testResult.JSON201.Id)
+		if err != nil {
+			return false, &ConnectionError{Operation: OperationGetTestResult, Err: err}
+		}
+		if response.JSON200 == nil {
+			return false, &APIError{
+				Operation:  OperationGetTestResult,
+				StatusCode: response.StatusCode(),
+				Body:       string(response.Body),
 			}
 		}
-
-		// Return the final result.
-		response, err := c.GetTestResultWithResponse(ctx, testResultId)
-		return response.JSON200, err
+		lastResult = response.JSON200
+		return !lastResult.InProgress, nil
+	})
+	if err != nil && pollCtx.Err() != nil {
+		return lastResult, &TestTimeoutError{TestResultId: testResult.JSON201.Id, Timeout: timeout, Err: pollCtx.Err()}
 	}
-	return nil, fmt.Errorf("couldn't launch on new test on Microcks. Please check Microcks container logs")
+	if err != nil {
+		return nil, err
+	}
+	return lastResult, nil
 }
 
 // TestEndpointAsync launches a conformance test on an endpoint and will provide result via a channel.
@@ -409,46 +593,38 @@ func (container *MicrocksContainer) TestEndpointAsync(ctx context.Context, testR
 
 // MessagesForTestCase retrieves messages exchanged during a test on an endpoint.
 func (container *MicrocksContainer) MessagesForTestCase(ctx context.Context, testResult *client.TestResult, operationName string) (*[]client.RequestResponsePair, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
-	}
-
-	// Create Microcks client.
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
+	c, err := container.Client(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating Microcks client: %w", err)
+		return nil, err
 	}
 
 	// Build the test case identifier and call api.
-	operation := encodeOperationName(operationName)
+	operation := EncodeOperationName(operationName)
 	testCaseId := fmt.Sprintf("%s-%s-%s", testResult.Id, strconv.Itoa(int(testResult.TestNumber)), operation)
 
 	response, err := c.GetMessagesByTestCaseWithResponse(ctx, testResult.Id, testCaseId)
-	return response.JSON200, err
+	if err != nil {
+		return nil, err
+	}
+	return response.JSON200, nil
 }
 
 // EventMessagesForTestCase retrieves event messages received during a test on an endpoint.
 func (container *MicrocksContainer) EventMessagesForTestCase(ctx context.Context, testResult *client.TestResult, operationName string) (*[]client.UnidirectionalEvent, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
-	}
-
-	// Create Microcks client.
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
+	c, err := container.Client(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating Microcks client: %w", err)
+		return nil, err
 	}
 
 	// Build the test case identifier and call api.
-	operation := encodeOperationName(operationName)
+	operation := EncodeOperationName(operationName)
 	testCaseId := fmt.Sprintf("%s-%s-%s", testResult.Id, strconv.Itoa(int(testResult.TestNumber)), operation)
 
 	response, err := c.GetEventsByTestCaseWithResponse(ctx, testResult.Id, testCaseId)
-	return response.JSON200, err
+	if err != nil {
+		return nil, err
+	}
+	return response.JSON200, nil
 }
 
 // Verify checks that given Service has been invoked at least one time, for the current invocations' date.
@@ -458,7 +634,18 @@ func (container *MicrocksContainer) Verify(ctx context.Context, serviceName stri
 
 // VerifyAtDate checks that given Service has been invoked at least one time, for the given invocations' date.
 func (container *MicrocksContainer) VerifyAtDate(ctx context.Context, serviceName string, serviceVersion string, date time.Time) (bool, error) {
-	invocationsCount, err := container.ServiceInvocationsCount(ctx, serviceName, serviceVersion)
+	count := func(ctx context.Context) (int, error) {
+		return container.ServiceInvocationsCountAtDate(ctx, serviceName, serviceVersion, date)
+	}
+
+	var invocationsCount int
+	var err error
+	if container.formatDay(date) == container.formatDay(time.Now()) {
+		// Give Microcks a chance to process today's invocations.
+		invocationsCount, err = container.settledCount(ctx, 1, count)
+	} else {
+		invocationsCount, err = count(ctx)
+	}
 	if err != nil {
 		return false, fmt.Errorf("cannot retrieve invocations stats: %w", err)
 	}
@@ -473,57 +660,45 @@ func (container *MicrocksContainer) ServiceInvocationsCount(ctx context.Context,
 }
 
 // ServiceInvocationsCountAtDate gets the invocations' count for a given service, identified by its name and version, for the given invocations' date.
+// The day of date is taken in the invocations timezone (see WithInvocationsTimezone).
+// As Microcks processes metrics asynchronously, use WaitForInvocations to count invocations that just happened.
 func (container *MicrocksContainer) ServiceInvocationsCountAtDate(ctx context.Context, serviceName string, serviceVersion string, date time.Time) (int, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
+	stats, err := container.serviceInvocationStats(ctx, serviceName, serviceVersion, date)
 	if err != nil {
-		return 0, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
+		return 0, err
 	}
+	return stats.DailyCount, nil
+}
 
-	// Create Microcks client.
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
-	if err != nil {
-		return 0, fmt.Errorf("error creating Microcks client: %w", err)
+func importArtifactAction(artifactFilePath string, mainArtifact bool) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		_, err := container.importArtifact(ctx, artifactFilePath, mainArtifact)
+		return err
 	}
+}
 
-	// Build the day.
-	day := formatDay(date)
-
-	// To avoid race condition issue while Microcks server is processing metrics asynchronously.
-	// The wait time is lower on other language bindings (100ms). Don't know why...
-	time.Sleep(250 * time.Millisecond)
-	stats, err := c.GetInvocationStatsByServiceWithResponse(ctx, serviceName, serviceVersion, &client.GetInvocationStatsByServiceParams{
-		Day: &day,
-	})
-
-	invocationStats := stats.JSON200
-	if invocationStats != nil {
-		return int(stats.JSON200.DailyCount), err
+// artifactPhase returns the import plan phase for a main or secondary artifact.
+func artifactPhase(main bool) importPhase {
+	if main {
+		return phaseMainArtifacts
 	}
-	return 0, err
+	return phaseSecondaryArtifacts
 }
 
-func importArtifactHook(artifactFilePath string, mainArtifact bool) testcontainers.ContainerHook {
-	return func(ctx context.Context, container testcontainers.Container) error {
-		microcksContainer := &MicrocksContainer{Container: container}
-		_, err := microcksContainer.importArtifact(ctx, artifactFilePath, mainArtifact)
+func importArtifactFSAction(fsys fs.FS, name string, mainArtifact bool) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return fmt.Errorf("error opening artifact file: %w", err)
+		}
+		defer file.Close()
+
+		_, err = container.uploadArtifact(ctx, name, path.Base(name), file, mainArtifact)
 		return err
 	}
 }
 
 func (container *MicrocksContainer) importArtifact(ctx context.Context, artifactFilePath string, mainArtifact bool) (int, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
-	}
-
-	// Create Microcks client.
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error creating Microcks client: %w", err)
-	}
-
 	// Ensure file exists on fs.
 	file, err := os.Open(artifactFilePath)
 	if err != nil {
@@ -531,37 +706,71 @@ func (container *MicrocksContainer) importArtifact(ctx context.Context, artifact
 	}
 	defer file.Close()
 
-	// Create a multipart request body, reading the file.
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", filepath.Base(artifactFilePath))
+	return container.uploadArtifact(ctx, artifactFilePath, filepath.Base(artifactFilePath), file, mainArtifact)
+}
+
+// uploadArtifact streams content to Microcks as an artifact file named fileName.
+// artifact identifies the artifact in returned errors.
+func (container *MicrocksContainer) uploadArtifact(ctx context.Context, artifact string, fileName string, content io.Reader, mainArtifact bool) (int, error) {
+	c, err := container.Client(ctx)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error creating multipart form: %w", err)
+		return http.StatusInternalServerError, &ConnectionError{Operation: OperationImportArtifact, Err: err}
 	}
 
-	_, err = io.Copy(part, file)
+	// Stream a multipart request body with the mainArtifact flag.
+	body, contentType, stop := streamMultipart(fileName, content, map[string]string{
+		"mainArtifact": strconv.FormatBool(mainArtifact),
+	})
+	defer stop()
+
+	response, err := c.UploadArtifactWithBody(ctx, nil, contentType, body)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error copying file to multipart form: %w", err)
+		return 0, &ConnectionError{Operation: OperationImportArtifact, Err: err}
 	}
+	defer response.Body.Close()
 
-	// Add the mainArtifact flag to request.
-	_ = writer.WriteField("mainArtifact", strconv.FormatBool(mainArtifact))
-	err = writer.Close()
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error closing multipart form: %w", err)
+	if !isSuccess(response.StatusCode) {
+		return response.StatusCode, newAPIError(OperationImportArtifact, artifact, response)
 	}
+	return response.StatusCode, nil
+}
 
-	response, err := c.UploadArtifactWithBody(ctx, nil, writer.FormDataContentType(), body)
-	if err != nil {
-		return 0, err
+// streamMultipart builds a multipart form body holding content as a "file" part, followed by fields.
+// The body is produced on the fly through a pipe so that content is never buffered as a whole.
+// Call stop once done with the body: it stops the production and waits for it to end, so that
+// content is not read anymore when it returns.
+func streamMultipart(fileName string, content io.Reader, fields map[string]string) (body io.Reader, contentType string, stop func()) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		part, err := writer.CreateFormFile("file", fileName)
+		if err == nil {
+			_, err = io.Copy(part, content)
+		}
+		for key, value := range fields {
+			if err == nil {
+				err = writer.WriteField(key, value)
+			}
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, writer.FormDataContentType(), func() {
+		pr.Close()
+		<-done
 	}
-	return response.StatusCode, err
 }
 
-func importSnapshotHook(snapshotFilePath string) testcontainers.ContainerHook {
-	return func(ctx context.Context, container testcontainers.Container) error {
-		microcksContainer := &MicrocksContainer{Container: container}
-		_, err := microcksContainer.importSnapshot(ctx, snapshotFilePath)
+func importSnapshotAction(snapshotFilePath string) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		_, err := container.importSnapshot(ctx, snapshotFilePath)
 		return err
 	}
 }
@@ -570,7 +779,7 @@ func (container *MicrocksContainer) importSnapshot(ctx context.Context, snapshot
 	// Retrieve API endpoint.
 	httpEndpoint, err := container.HttpEndpoint(ctx)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
+		return http.StatusInternalServerError, &ConnectionError{Operation: OperationImportSnapshot, Err: err}
 	}
 
 	// Ensure file exists on fs.
@@ -580,48 +789,32 @@ func (container *MicrocksContainer) importSnapshot(ctx context.Context, snapshot
 	}
 	defer file.Close()
 
-	// Create a multipart request body, reading the file.
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", filepath.Base(snapshotFilePath))
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error creating multipart form: %w", err)
-	}
-
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error copying file to multipart form: %w", err)
-	}
-
-	err = writer.Close()
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error closing multipart form: %w", err)
-	}
+	// Stream a multipart request body, reading the file.
+	body, contentType, stop := streamMultipart(filepath.Base(snapshotFilePath), file, nil)
+	defer stop()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, httpEndpoint+"/api/import", body)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Type", contentType)
 
-	response, err := http.DefaultClient.Do(req)
+	response, err := container.apiHTTPClient().Do(req)
 	if err != nil {
-		return 0, err
+		return 0, &ConnectionError{Operation: OperationImportSnapshot, Err: err}
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(response.Body)
-		return response.StatusCode, fmt.Errorf("snapshot has not been correctly imported: %s", string(bodyBytes))
+	if !isSuccess(response.StatusCode) {
+		return response.StatusCode, newAPIError(OperationImportSnapshot, snapshotFilePath, response)
 	}
 
 	return response.StatusCode, nil
 }
 
-func downloadArtifactHook(remoteArtifactUrl string, mainArtifact bool, secretName ...string) testcontainers.ContainerHook {
-	return func(ctx context.Context, container testcontainers.Container) error {
-		microcksContainer := &MicrocksContainer{Container: container}
-		_, err := microcksContainer.downloadArtifact(ctx, remoteArtifactUrl, mainArtifact, secretName...)
+func downloadArtifactAction(remoteArtifactUrl string, mainArtifact bool, secretName ...string) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		_, err := container.downloadArtifact(ctx, remoteArtifactUrl, mainArtifact, secretName...)
 		return err
 	}
 }
@@ -630,7 +823,7 @@ func (container *MicrocksContainer) downloadArtifact(ctx context.Context, remote
 	// Retrieve API endpoint.
 	httpEndpoint, err := container.HttpEndpoint(ctx)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
+		return http.StatusInternalServerError, &ConnectionError{Operation: OperationDownloadArtifact, Err: err}
 	}
 
 	data := url.Values{}
@@ -646,154 +839,91 @@ func (container *MicrocksContainer) downloadArtifact(ctx context.Context, remote
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	response, err := http.DefaultClient.Do(req)
+	response, err := container.apiHTTPClient().Do(req)
 	if err != nil {
-		return 0, err
+		return 0, &ConnectionError{Operation: OperationDownloadArtifact, Err: err}
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(response.Body)
-		return response.StatusCode, fmt.Errorf("artifact has not been correctly downloaded: %s", string(bodyBytes))
+	if !isSuccess(response.StatusCode) {
+		return response.StatusCode, newAPIError(OperationDownloadArtifact, remoteArtifactUrl, response)
 	}
 
 	return response.StatusCode, nil
 }
 
-func createSecretHook(s client.Secret) testcontainers.ContainerHook {
-	return func(ctx context.Context, container testcontainers.Container) error {
-		microcksContainer := &MicrocksContainer{Container: container}
-		_, err := microcksContainer.createSecret(ctx, s)
+func createSecretAction(s client.Secret) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		_, err := container.createSecret(ctx, s)
 		return err
 	}
 }
 
 func (container *MicrocksContainer) createSecret(ctx context.Context, s client.Secret) (int, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
-	}
-
-	// Create Microcks client.
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
+	c, err := container.Client(ctx)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error creating Microcks client: %w", err)
+		return http.StatusInternalServerError, &ConnectionError{Operation: OperationCreateSecret, Err: err}
 	}
 
 	// Create secret.
-	response, err := c.CreateSecret(ctx, s, nil)
+	response, err := c.CreateSecret(ctx, s)
 	if err != nil {
-		return 0, err
+		return 0, &ConnectionError{Operation: OperationCreateSecret, Err: err}
 	}
-	return response.StatusCode, err
-}
+	defer response.Body.Close()
 
-func nowInMilliseconds() int64 {
-	return time.Now().UnixNano() / int64(time.Millisecond)
+	if !isSuccess(response.StatusCode) {
+		return response.StatusCode, newAPIError(OperationCreateSecret, "", response)
+	}
+	return response.StatusCode, nil
 }
 
-func encodeOperationName(operationName string) string {
+// EncodeOperationName encodes an operation name the way Microcks does in test case identifiers,
+// e.g. "GET /pastries/{name}" becomes "GET !pastries!{name}".
+func EncodeOperationName(operationName string) string {
 	return strings.ReplaceAll(operationName, "/", "!")
 }
 
-func formatDay(date time.Time) string {
-	return date.Format("20060102")
+// formatDay formats the day of date, as seen in the invocations timezone, the way Microcks expects it.
+func (container *MicrocksContainer) formatDay(date time.Time) string {
+	return date.In(container.invocationsTimezone()).Format("20060102")
 }
 
 // WebhookCoordinates identifies a webhook to register: the service it belongs
 // to (as "name:version"), the operation name, and where Microcks should push events.
+// Zero tuning fields let Microcks apply its own defaults (3000ms, 2 days and 5 errors).
 type WebhookCoordinates struct {
 	ServiceId     string
 	OperationName string
 	TargetUrl     string
+	// Frequency is the interval between two events pushed to TargetUrl, with a millisecond precision.
+	Frequency time.Duration
+	// ExpiresAt is the time after which no more events are pushed.
+	ExpiresAt time.Time
+	// ErrorCountThreshold is the number of failed pushes after which the webhook is disabled.
+	ErrorCountThreshold int
+
+	// receiver is the WebhookReceiver TargetUrl points to, if any.
+	receiver *WebhookReceiver
 }
 
 // WithWebhookRegistration allows registering one or more webhooks in Microcks,
-// once the container is ready.
+// once the container is ready. The WebhookReceiver of coordinates, if any, is exposed to the container.
 func WithWebhookRegistration(coordinates ...WebhookCoordinates) testcontainers.CustomizeRequestOption {
-	return func(req *testcontainers.GenericContainerRequest) error {
-		hooks := testcontainers.ContainerLifecycleHooks{
-			PostReadies: []testcontainers.ContainerHook{
-				registerWebhooksHook(coordinates),
-			},
-		}
-		req.LifecycleHooks = append(req.LifecycleHooks, hooks)
-
-		return nil
-	}
-}
-
-func registerWebhooksHook(coordinates []WebhookCoordinates) testcontainers.ContainerHook {
-	return func(ctx context.Context, container testcontainers.Container) error {
-		microcksContainer := &MicrocksContainer{Container: container}
+	return Option(func(o *options) error {
 		for _, wc := range coordinates {
-			if _, err := microcksContainer.registerWebhook(ctx, wc); err != nil {
-				return err
+			if wc.receiver != nil {
+				o.hostAccessPorts = append(o.hostAccessPorts, wc.receiver.Port())
 			}
+			o.plan.add(phaseWebhooks, fmt.Sprintf("webhook %s on %s", wc.OperationName, wc.ServiceId), registerWebhookAction(wc))
 		}
 		return nil
-	}
+	}).Customize
 }
 
-func (container *MicrocksContainer) registerWebhook(ctx context.Context, coordinates WebhookCoordinates) (int, error) {
-	// Retrieve API endpoint.
-	httpEndpoint, err := container.HttpEndpoint(ctx)
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error retrieving Microcks API endpoint: %w", err)
-	}
-
-	// Create Microcks client (used here just for the services lookup).
-	c, err := client.NewClientWithResponses(httpEndpoint + "/api")
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error creating Microcks client: %w", err)
-	}
-
-	// Find the correct technical serviceId from the functional "name:version".
-	parts := strings.SplitN(coordinates.ServiceId, ":", 2)
-	if len(parts) != 2 {
-		return http.StatusBadRequest, fmt.Errorf("invalid serviceId format, expected 'name:version', got %q", coordinates.ServiceId)
-	}
-	name, version := parts[0], parts[1]
-
-	// The services endpoint paginates with a default page size of 20, so ask for a larger page.
-	servicesPageSize := 100
-	servicesResp, err := c.GetServicesWithResponse(ctx, &client.GetServicesParams{Size: &servicesPageSize})
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error listing services: %w", err)
-	}
-	if servicesResp.JSON200 == nil {
-		return http.StatusInternalServerError, fmt.Errorf("couldn't retrieve services list from Microcks")
-	}
-
-	var serviceId string
-	for _, s := range *servicesResp.JSON200 {
-		if s.Name == name && s.Version == version {
-			serviceId = *s.Id
-			break
-		}
-	}
-	if serviceId == "" {
-		return http.StatusNotFound, fmt.Errorf("service %s:%s not found in Microcks container", name, version)
-	}
-
-	operationId := serviceId + "-" + coordinates.OperationName
-
-	// Frequency, ExpiresAt and ErrorCountThreshold are left unset: Microcks applies
-	// its own defaults (3000ms, 2 days and 5 errors).
-	response, err := c.RegisterWebhook(ctx, client.WebhookRegistrationRequest{
-		OperationId: operationId,
-		TargetUrl:   coordinates.TargetUrl,
-	})
-	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("error registering webhook: %w", err)
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusCreated {
-		respBody, _ := io.ReadAll(response.Body)
-		return response.StatusCode, fmt.Errorf("couldn't create webhook registration on Microcks: %s", string(respBody))
+func registerWebhookAction(coordinates WebhookCoordinates) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		_, err := container.RegisterWebhook(ctx, coordinates)
+		return err
 	}
-
-	return response.StatusCode, nil
 }
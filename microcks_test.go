@@ -19,13 +19,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
-	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
-	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -34,8 +36,13 @@ import (
 
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	client "microcks.io/go-client"
 	microcks "microcks.io/testcontainers-go"
+	"microcks.io/testcontainers-go/artifacts"
+	"microcks.io/testcontainers-go/dispatch"
 	"microcks.io/testcontainers-go/internal/test"
+	"microcks.io/testcontainers-go/mockdsl"
+	"microcks.io/testcontainers-go/secrets"
 )
 
 func TestMockingFunctionalityAtStartup(t *testing.T) {
@@ -75,13 +82,7 @@ func TestMockingFunctionalityAtStartup(t *testing.T) {
 func TestMockingFunctionality(t *testing.T) {
 	ctx := context.Background()
 
-	microcksContainer, err := microcks.Run(ctx, "quay.io/microcks/microcks-uber:nightly")
-	require.NoError(t, err)
-	t.Cleanup(func() {
-		if err := microcksContainer.Terminate(ctx); err != nil {
-			t.Fatalf("failed to terminate container: %s", err)
-		}
-	})
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly")
 
 	// Loading artifacts.
 	status, err := microcksContainer.ImportAsMainArtifact(ctx, filepath.Join("testdata", "apipastries-openapi.yaml"))
@@ -94,6 +95,7 @@ func TestMockingFunctionality(t *testing.T) {
 
 	test.ConfigRetrieval(t, ctx, microcksContainer)
 	test.MockEndpoints(t, ctx, microcksContainer)
+	test.ServiceCatalog(t, ctx, microcksContainer)
 
 	test.MicrocksMockingFunctionality(t, ctx, microcksContainer)
 }
@@ -177,32 +179,15 @@ func TestContractTestingFunctionality(t *testing.T) {
 func TestWebhookFunctionality(t *testing.T) {
 	ctx := context.Background()
 
-	var mu sync.Mutex
-	var received []string
-
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		body, _ := io.ReadAll(r.Body)
-		mu.Lock()
-		received = append(received, string(body))
-		mu.Unlock()
-		w.WriteHeader(http.StatusOK)
-	}))
-	defer server.Close()
+	receiver := microcks.NewWebhookReceiver(microcks.WithSchemaValidation())
+	defer receiver.Close()
 
-	// Extract the port from the httptest server so we can expose it to the container.
-	serverURL, err := url.Parse(server.URL)
-	require.NoError(t, err)
-	port, err := strconv.Atoi(serverURL.Port())
-	require.NoError(t, err)
+	webhook := receiver.Webhook("Petstore Webhooks:2.0.0", "newPet WEBHOOK")
+	webhook.Frequency = 200 * time.Millisecond
 
 	microcksContainer, err := microcks.Run(ctx, "quay.io/microcks/microcks-uber:nightly",
-		microcks.WithHostAccessPorts([]int{port}),
 		microcks.WithMainArtifact("testdata/petstore-webhooks-openapi.yaml"),
-		microcks.WithWebhookRegistration(microcks.WebhookCoordinates{
-			ServiceId:     "Petstore Webhooks:2.0.0",
-			OperationName: "newPet WEBHOOK",
-			TargetUrl:     fmt.Sprintf("http://host.testcontainers.internal:%d", port),
-		}),
+		microcks.WithWebhookRegistration(webhook),
 	)
 	require.NoError(t, err)
 	t.Cleanup(func() {
@@ -210,42 +195,99 @@ func TestWebhookFunctionality(t *testing.T) {
 			t.Fatalf("failed to terminate container: %s", err)
 		}
 	})
-  
-    // Poll for webhook messages (Microcks pushes them every 3s).
-	deadline := time.Now().Add(10 * time.Second)
-	for time.Now().Before(deadline) {
-		mu.Lock()
-		if len(received) > 0 {
-			mu.Unlock()
-			break
-		}
-		mu.Unlock()
-		time.Sleep(100 * time.Millisecond)
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
 
-	require.NotEmpty(t, received, "webhook server should have received at least one message")
+	// Wait for webhook messages (Microcks pushes them every 200ms).
+	awaitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, err = receiver.Await(awaitCtx, 3)
+	require.NoError(t, err, "webhook receiver should have received several messages")
 
-	// Check the content of every received message matches the 'Rusty' example.
-	for _, msg := range received {
+	// Check the content of every received message matches the 'Rusty' example, and holds the properties required by the Pet schema.
+	err = receiver.AssertEach(func(body []byte) error {
 		var payload struct {
 			Id   int    `json:"id"`
 			Name string `json:"name"`
 			Tag  string `json:"tag"`
 		}
-		err := json.Unmarshal([]byte(msg), &payload)
-		require.NoError(t, err, "received message should be valid JSON")
-
-		require.Equal(t, "Rusty", payload.Name)
-		require.True(t, payload.Id >= 1 && payload.Id <= 10,
-			"id should be a random int between 1 and 10 but was %d", payload.Id)
-		require.Contains(t, []string{"cat", "dog"}, payload.Tag,
-			"tag should be either 'cat' or 'dog' but was %q", payload.Tag)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return err
+		}
+		if payload.Name != "Rusty" {
+			return fmt.Errorf("name should be 'Rusty' but was %q", payload.Name)
+		}
+		if payload.Id < 1 || payload.Id > 10 {
+			return fmt.Errorf("id should be a random int between 1 and 10 but was %d", payload.Id)
+		}
+		if payload.Tag != "cat" && payload.Tag != "dog" {
+			return fmt.Errorf("tag should be either 'cat' or 'dog' but was %q", payload.Tag)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Every registered webhook can be listed and unregistered.
+	second := receiver.Webhook("Petstore Webhooks:2.0.0", "newPet WEBHOOK")
+	second.Frequency = 200 * time.Millisecond
+	second.ExpiresAt = time.Now().Add(time.Minute)
+	second.ErrorCountThreshold = 2
+	webhookId, err := microcksContainer.RegisterWebhook(ctx, second)
+	require.NoError(t, err)
+	require.NotEmpty(t, webhookId)
+
+	webhooks, err := microcksContainer.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	for _, webhook := range webhooks {
+		require.Equal(t, 200*time.Millisecond, webhook.Interval())
+		require.NoError(t, microcksContainer.UnregisterWebhook(ctx, webhook.Id))
 	}
+	webhooks, err = microcksContainer.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Empty(t, webhooks, "no webhook should be left to push messages")
+
+	_, err = microcksContainer.RegisterWebhook(ctx, microcks.WebhookCoordinates{
+		ServiceId:     "Petstore Webhooks:2.0.0",
+		OperationName: "newPet WEBHOOK",
+		TargetUrl:     "host.testcontainers.internal",
+		Frequency:     -time.Second,
+	})
+	require.ErrorContains(t, err, "is not an absolute URL")
+	require.ErrorContains(t, err, "is too short")
 }
-  
+
+func TestWebhookReceiver(t *testing.T) {
+	receiver := microcks.NewWebhookReceiver()
+	defer receiver.Close()
+
+	webhook := receiver.Webhook("Petstore Webhooks:2.0.0", "newPet WEBHOOK")
+	require.Equal(t, fmt.Sprintf("http://host.testcontainers.internal:%d/webhooks/1", receiver.Port()), webhook.TargetUrl)
+	require.EqualError(t, receiver.AssertEach(nil), "no webhook message received")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := receiver.Await(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "received 0 of 1 webhook messages")
+
+	for _, body := range []string{`{"name": "Rusty"}`, `{"name": "Medor"}`} {
+		response, err := http.Post(fmt.Sprintf("http://localhost:%d/webhooks/1", receiver.Port()), "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		response.Body.Close()
+	}
+
+	messages, err := receiver.Await(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`{"name": "Rusty"}`), []byte(`{"name": "Medor"}`)}, messages)
+
+	err = receiver.AssertEach(func(body []byte) error {
+		if !bytes.Contains(body, []byte("Rusty")) {
+			return errors.New("not Rusty")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "message 1: not Rusty")
+}
+
 func TestRemoteArtifactDownload(t *testing.T) {
 	ctx := context.Background()
 
@@ -296,3 +338,570 @@ func TestRemoteArtifactDownloadImperative(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+type countingTransport struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.mu.Lock()
+	ct.count++
+	ct.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestCustomHTTPClient(t *testing.T) {
+	ctx := context.Background()
+
+	transport := &countingTransport{}
+	microcksContainer, err := microcks.Run(ctx, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithTransport(transport),
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := microcksContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// Artifact import at startup should have gone through our transport.
+	transport.mu.Lock()
+	require.Equal(t, 1, transport.count)
+	transport.mu.Unlock()
+
+	// Client is built once and shared.
+	c, err := microcksContainer.Client(ctx)
+	require.NoError(t, err)
+	c2, err := microcksContainer.Client(ctx)
+	require.NoError(t, err)
+	require.Same(t, c, c2)
+
+	response, err := c.GetServicesWithResponse(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode())
+
+	transport.mu.Lock()
+	require.Equal(t, 2, transport.count)
+	transport.mu.Unlock()
+}
+
+func TestOptionsOutsideRun(t *testing.T) {
+	// Import options keep working with plain testcontainers requests, through a post-ready hook.
+	var opts []testcontainers.CustomizeRequestOption
+	opts = append(opts,
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+		microcks.WithSecondaryArtifact("testdata/apipastries-postman-collection.json"),
+	)
+	req := testcontainers.GenericContainerRequest{}
+	for _, opt := range opts {
+		require.NoError(t, opt.Customize(&req))
+	}
+	require.Len(t, req.LifecycleHooks, 2)
+
+	// Options only configuring the MicrocksContainer are rejected instead of being ignored.
+	require.Error(t, microcks.WithHTTPClient(http.DefaultClient).Customize(&testcontainers.GenericContainerRequest{}))
+}
+
+func TestImportErrors(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer, err := microcks.Run(ctx, "quay.io/microcks/microcks-uber:nightly")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := microcksContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	// Importing something that is not an API artifact should be rejected by Microcks.
+	status, err := microcksContainer.ImportAsMainArtifact(ctx, "go.mod")
+	require.Error(t, err)
+
+	var apiErr *microcks.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, microcks.OperationImportArtifact, apiErr.Operation)
+	require.Equal(t, status, apiErr.StatusCode)
+	require.Equal(t, "go.mod", apiErr.Artifact)
+
+	var connErr *microcks.ConnectionError
+	require.False(t, errors.As(err, &connErr))
+}
+
+func TestImportFromReaderAndFS(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer, err := microcks.Run(ctx, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithArtifactsFS(os.DirFS("testdata"), "apipastries-openapi.yaml"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := microcksContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	collection, err := os.Open(filepath.Join("testdata", "apipastries-postman-collection.json"))
+	require.NoError(t, err)
+	defer collection.Close()
+
+	status, err := microcksContainer.ImportArtifactFromReader(ctx, "apipastries-postman-collection.json", collection, false)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, status)
+
+	test.MicrocksMockingFunctionality(t, ctx, microcksContainer)
+}
+
+func TestDetectArtifactType(t *testing.T) {
+	artifacts, err := microcks.FindArtifacts("testdata", microcks.ArtifactsDirOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []microcks.ArtifactFile{
+		{Path: filepath.Join("testdata", "apipastries-openapi.yaml"), Type: microcks.ArtifactTypeOpenAPI, Main: true},
+		{Path: filepath.Join("testdata", "pastry-orders-asyncapi.yaml"), Type: microcks.ArtifactTypeAsyncAPI, Main: true},
+		{Path: filepath.Join("testdata", "petstore-webhooks-openapi.yaml"), Type: microcks.ArtifactTypeOpenAPI, Main: true},
+		{Path: filepath.Join("testdata", "apipastries-postman-collection.json"), Type: microcks.ArtifactTypePostman, Main: false},
+	}, artifacts)
+
+	cases := map[string]struct {
+		name     string
+		content  string
+		expected microcks.ArtifactType
+	}{
+		"metadata": {"metadata.yml", "apiVersion: mocks.microcks.io/v1alpha1\nkind: APIMetadata\n", microcks.ArtifactTypeAPIMetadata},
+		"examples": {"examples.yml", "apiVersion: mocks.microcks.io/v1alpha1\nkind: APIExamples\n", microcks.ArtifactTypeAPIExamples},
+		"proto":    {"pastries.txt", "syntax = \"proto3\";\npackage io.github.microcks;\n", microcks.ArtifactTypeGRPC},
+		"graphql":  {"schema.txt", "type Query {\n  allPastries: [Pastry]\n}\n", microcks.ArtifactTypeGraphQL},
+		"soapui":   {"project.xml", "<?xml version=\"1.0\"?>\n<con:soapui-project name=\"Pastries\">", microcks.ArtifactTypeSoapUI},
+		"har":      {"traffic.json", "{\"log\": {\"version\": \"1.2\", \"entries\": []}}", microcks.ArtifactTypeHAR},
+		"swagger":  {"swagger.json", "{\"swagger\": \"2.0\"}", microcks.ArtifactTypeOpenAPI},
+	}
+	for name, c := range cases {
+		artifactType, ok := microcks.DetectArtifactType(c.name, []byte(c.content))
+		require.True(t, ok, name)
+		require.Equal(t, c.expected, artifactType, name)
+	}
+
+	_, ok := microcks.DetectArtifactType("README.md", []byte("# Microcks"))
+	require.False(t, ok)
+}
+
+func TestImportPlanErrors(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer, err := microcks.Run(ctx, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithSecondaryArtifact("testdata/apipastries-postman-collection.json"),
+		microcks.WithMainArtifact("go.mod"),
+		microcks.WithMainArtifact("testdata/unknown-openapi.yaml"),
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+	)
+	require.Error(t, err)
+
+	// Container is returned along with the error so that it can be terminated.
+	require.NotNil(t, microcksContainer)
+	testcontainers.CleanupContainer(t, microcksContainer)
+
+	// Every failing artifact is reported, not only the first one.
+	require.ErrorContains(t, err, "artifact go.mod")
+	require.ErrorContains(t, err, "artifact testdata/unknown-openapi.yaml")
+	require.NotContains(t, err.Error(), "apipastries")
+
+	var apiErr *microcks.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "go.mod", apiErr.Artifact)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestTestRequestBuilder(t *testing.T) {
+	testRequest, err := microcks.NewTestRequest("API Pastries", "0.0.1").
+		Runner(client.TestRunnerTypeOPENAPISCHEMA).
+		Endpoint("http://good-impl:3002").
+		Timeout(2 * time.Second).
+		OnlyOperations("GET /pastries").
+		Header("GET /pastries", "X-Trace", "1").
+		Header("GET /pastries", "X-Trace", "2").
+		Header("globals", "Accept", "application/json").
+		WithSecret("my-secret").
+		WithOAuth2ClientCredentials("http://keycloak:8080/realms/dev/protocol/openid-connect/token", "client", "s3cr3t", "openid", "pastries").
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, "API Pastries:0.0.1", testRequest.ServiceId)
+	require.Equal(t, client.TestRunnerTypeOPENAPISCHEMA, testRequest.RunnerType)
+	require.Equal(t, "http://good-impl:3002", testRequest.TestEndpoint)
+	require.EqualValues(t, 2000, testRequest.Timeout)
+	require.Equal(t, []string{"GET /pastries"}, *testRequest.FilteredOperations)
+	require.Equal(t, "my-secret", *testRequest.SecretName)
+
+	// Values of a same header are comma separated.
+	require.Equal(t, client.OperationHeaders{
+		"GET /pastries": {{Name: "X-Trace", Values: "1,2"}},
+		"globals":       {{Name: "Accept", Values: "application/json"}},
+	}, *testRequest.OperationsHeaders)
+
+	oAuth2 := testRequest.OAuth2Context
+	require.NotNil(t, oAuth2)
+	require.Equal(t, client.OAuth2GrantTypeCLIENTCREDENTIALS, oAuth2.GrantType)
+	require.Equal(t, "http://keycloak:8080/realms/dev/protocol/openid-connect/token", oAuth2.TokenUri)
+	require.Equal(t, "client", oAuth2.ClientId)
+	require.Equal(t, "s3cr3t", oAuth2.ClientSecret)
+	require.Equal(t, "openid pastries", *oAuth2.Scopes)
+
+	// All the problems are reported at once.
+	_, err = microcks.NewTestRequest("API Pastries", "").
+		Runner("UNKNOWN").
+		Endpoint("good-impl").
+		OnlyOperations("GET /pastries").
+		Header("POST /orders", "X-Trace", "1").
+		WithOAuth2ClientCredentials("not a url", "client", "").
+		Build()
+	require.ErrorContains(t, err, "service name and version are required")
+	require.ErrorContains(t, err, `unknown runner "UNKNOWN"`)
+	require.ErrorContains(t, err, `endpoint "good-impl" is not an absolute URL`)
+	require.ErrorContains(t, err, `operation "POST /orders" that is not tested`)
+	require.ErrorContains(t, err, "OAuth2 client id and secret are required")
+	require.ErrorContains(t, err, "OAuth2 token URI")
+}
+
+func TestReset(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+		microcks.WithMainArtifact("testdata/petstore-webhooks-openapi.yaml"),
+		microcks.WithSecret(secrets.Must(secrets.BasicAuth("startup secret", "admin", "s3cr3t"))),
+	)
+
+	// Secrets can be found and updated.
+	secret, err := microcksContainer.FindSecretByName(ctx, "startup secret")
+	require.NoError(t, err)
+	require.Equal(t, "startup secret", secret.Name)
+	require.NoError(t, microcksContainer.UpdateSecret(ctx, secrets.Must(secrets.Token("startup secret", "X-Api-Key", "abc"))))
+	updated, err := microcksContainer.FindSecretByName(ctx, "startup secret")
+	require.NoError(t, err)
+	require.Equal(t, secret.Id, updated.Id, "secret is updated in place")
+	require.NotNil(t, updated.Token)
+	require.Equal(t, "abc", *updated.Token)
+	require.NotNil(t, updated.TokenHeader)
+	require.Equal(t, "X-Api-Key", *updated.TokenHeader)
+
+	_, err = microcksContainer.FindSecretByName(ctx, "unknown secret")
+	require.ErrorIs(t, err, microcks.ErrSecretNotFound)
+
+	services, err := microcksContainer.ListServices(ctx, microcks.ServiceFilter{Type: "REST"})
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+
+	// Test an unreachable endpoint, just to get a test result.
+	testResult, err := microcksContainer.TestEndpoint(ctx, &client.TestRequest{
+		ServiceId:    "API Pastries:0.0.1",
+		RunnerType:   client.TestRunnerTypeOPENAPISCHEMA,
+		TestEndpoint: "http://localhost:1",
+		Timeout:      2000,
+	})
+	require.NoError(t, err)
+
+	pastries := microcks.ServiceRef{Name: "API Pastries", Version: "0.0.1"}
+	report, err := microcksContainer.Reset(ctx, pastries)
+	require.NoError(t, err)
+	require.Len(t, report.Services, 1)
+	require.Empty(t, report.Secrets, "startup secrets are kept")
+	require.NotEqual(t, pastries, report.Services[0])
+
+	exists, err := microcksContainer.ServiceExists(ctx, pastries.Name, pastries.Version)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// Test results of kept services are kept too.
+	c, err := microcksContainer.Client(ctx)
+	require.NoError(t, err)
+	kept, err := c.GetTestResultWithResponse(ctx, testResult.Id)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, kept.StatusCode())
+	require.Equal(t, testResult.Id, kept.JSON200.Id)
+
+	// Deleting again what has been removed fails.
+	err = microcksContainer.DeleteService(ctx, report.Services[0].Name, report.Services[0].Version)
+	require.ErrorIs(t, err, microcks.ErrServiceNotFound)
+
+	require.NoError(t, microcksContainer.DeleteService(ctx, pastries.Name, pastries.Version))
+	report, err = microcksContainer.Reset(ctx)
+	require.NoError(t, err)
+	require.Empty(t, report.Services)
+}
+
+func TestSnapshotExport(t *testing.T) {
+	ctx := context.Background()
+	fixture := filepath.Join(t.TempDir(), "fixtures", "pastries-snapshot.json")
+	pastries := microcks.ServiceRef{Name: "API Pastries", Version: "0.0.1"}
+
+	// First run imports the source artifact and generates the fixture.
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/petstore-webhooks-openapi.yaml"),
+		microcks.WithSnapshotFixture(fixture, "testdata/apipastries-openapi.yaml"),
+	)
+	snapshot, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	require.Contains(t, string(snapshot), "API Pastries")
+	require.NotContains(t, string(snapshot), "Petstore Webhooks", "only services of the sources are exported")
+
+	var exported bytes.Buffer
+	require.NoError(t, microcksContainer.ExportSnapshot(ctx, &exported, pastries))
+	require.Contains(t, exported.String(), "API Pastries")
+
+	err = microcksContainer.ExportSnapshot(ctx, io.Discard, microcks.ServiceRef{Name: "Unknown", Version: "1.0"})
+	require.ErrorIs(t, err, microcks.ErrServiceNotFound)
+
+	// Next runs import the fixture, which is kept as is.
+	info, err := os.Stat(fixture)
+	require.NoError(t, err)
+	microcksContainer = microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithSnapshotFixture(fixture, "testdata/apipastries-openapi.yaml"),
+	)
+	exists, err := microcksContainer.ServiceExists(ctx, pastries.Name, pastries.Version)
+	require.NoError(t, err)
+	require.True(t, exists)
+	reloaded, err := os.Stat(fixture)
+	require.NoError(t, err)
+	require.Equal(t, info.ModTime(), reloaded.ModTime())
+}
+
+func TestOperationDispatcher(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+	)
+	baseApiUrl, err := microcksContainer.RestMockEndpoint(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+
+	getPastry := func(name string) (int, string) {
+		response, err := http.Get(baseApiUrl + "/pastries/" + url.PathEscape(name))
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(body)
+	}
+
+	// Unknown pastries fall back to the Millefeuille response.
+	restore, err := microcksContainer.SetOperationDispatcher(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}",
+		dispatch.Fallback, dispatch.FallbackRules(dispatch.URIParts, dispatch.Params("name"), "Millefeuille"))
+	require.NoError(t, err)
+	restore.Cleanup(t)
+
+	status, body := getPastry("Croissant")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "Millefeuille")
+	_, body = getPastry("Eclair Cafe")
+	require.Contains(t, body, "Eclair Cafe")
+
+	require.NoError(t, restore(ctx))
+	require.NoError(t, restore(ctx), "restoring is done once")
+	service, err := microcksContainer.GetService(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	operation, ok := service.Operation("GET /pastries/{name}")
+	require.True(t, ok)
+	require.Equal(t, dispatch.URIParts, operation.Dispatcher)
+	status, _ = getPastry("Croissant")
+	require.NotEqual(t, http.StatusOK, status)
+
+	_, err = microcksContainer.SetOperationDispatcher(ctx, "API Pastries", "0.0.1", "DELETE /pastries", dispatch.Random, "")
+	require.ErrorIs(t, err, microcks.ErrOperationNotFound)
+}
+
+func TestOperationDelayAndFaults(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+	)
+	baseApiUrl, err := microcksContainer.RestMockEndpoint(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+
+	getPastry := func(name string) string {
+		response, err := http.Get(baseApiUrl + "/pastries/" + url.PathEscape(name))
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	restore, err := microcksContainer.SetOperationDelay(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}", 500*time.Millisecond)
+	require.NoError(t, err)
+	restore.Cleanup(t)
+
+	start := time.Now()
+	getPastry("Millefeuille")
+	require.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+
+	require.NoError(t, restore(ctx))
+	service, err := microcksContainer.GetService(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	operation, _ := service.Operation("GET /pastries/{name}")
+	require.Zero(t, operation.Delay())
+
+	// The first two calls get the fault response, whatever the requested pastry.
+	restore, err = microcksContainer.SetOperationFaults(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}", microcks.FaultProfile{
+		Fault:    "Eclair Cafe",
+		Calls:    2,
+		Recovery: "Millefeuille",
+	})
+	require.NoError(t, err)
+	restore.Cleanup(t)
+
+	require.Contains(t, getPastry("Millefeuille"), "Eclair Cafe")
+	require.Contains(t, getPastry("Millefeuille"), "Eclair Cafe")
+	require.Contains(t, getPastry("Eclair Cafe"), "Millefeuille")
+
+	require.NoError(t, restore(ctx))
+	require.Contains(t, getPastry("Eclair Cafe"), "Eclair Cafe")
+
+	_, err = microcksContainer.SetOperationFaults(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}", microcks.FaultProfile{Calls: 2})
+	require.ErrorContains(t, err, "fault response is required")
+	require.ErrorContains(t, err, "recovery response is required")
+	_, err = microcksContainer.SetOperationDelay(ctx, "API Pastries", "0.0.1", "GET /pastries/{name}", -time.Second)
+	require.ErrorContains(t, err, "is negative")
+}
+
+func TestMockDSL(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly")
+
+	service := mockdsl.NewService("Pastries DSL", "1.0.0")
+	service.Operation(http.MethodGet, "/pastries/{name}").
+		Example("Millefeuille",
+			mockdsl.NewRequest().PathParam("name", "Millefeuille"),
+			mockdsl.NewResponse(http.StatusOK).JSON(map[string]any{"name": "Millefeuille", "price": 4.4})).
+		Example("Unknown",
+			mockdsl.NewRequest().PathParam("name", "Unknown"),
+			mockdsl.NewResponse(http.StatusNotFound).Text("Not found"))
+	status, err := service.Import(ctx, microcksContainer)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, status)
+
+	baseApiUrl, err := microcksContainer.RestMockEndpoint(ctx, "Pastries DSL", "1.0.0")
+	require.NoError(t, err)
+
+	response, err := http.Get(baseApiUrl + "/pastries/Millefeuille")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var pastry map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&pastry))
+	require.Equal(t, map[string]any{"name": "Millefeuille", "price": 4.4}, pastry)
+
+	response, err = http.Get(baseApiUrl + "/pastries/Unknown")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestSecondaryArtifactsBuilders(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+	)
+
+	examples := artifacts.Examples("API Pastries", "0.0.1")
+	examples.Operation("GET /pastries/{name}").
+		Example("Kouign-amann",
+			artifacts.Request{Parameters: map[string]string{"name": "Kouign-amann"}},
+			artifacts.Response{Body: map[string]any{"name": "Kouign-amann", "size": "M", "price": 3.5, "status": "available"}})
+	status, err := examples.Import(ctx, microcksContainer)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, status)
+
+	status, err = artifacts.Metadata("API Pastries", "0.0.1").Label("domain", "pastry").Import(ctx, microcksContainer)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, status)
+
+	baseApiUrl, err := microcksContainer.RestMockEndpoint(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+
+	// The added example answers alongside the ones of the base contract.
+	for name, price := range map[string]float64{"Kouign-amann": 3.5, "Millefeuille": 4.4} {
+		response, err := http.Get(baseApiUrl + "/pastries/" + name)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		var pastry map[string]any
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&pastry))
+		require.Equal(t, name, pastry["name"])
+		require.Equal(t, price, pastry["price"])
+	}
+}
+
+func TestMockEndpointURLs(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/apipastries-openapi.yaml"),
+	)
+
+	baseApiUrl, err := microcksContainer.RestMockEndpointURL(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "/rest/API%20Pastries/0.0.1", baseApiUrl.EscapedPath())
+
+	validApiUrl, err := microcksContainer.ValidatingSoapMockEndpointURL(ctx, "Pastries Service", "1.0")
+	require.NoError(t, err)
+	require.Equal(t, "/soap/Pastries%20Service/1.0", validApiUrl.EscapedPath())
+	query := validApiUrl.Query()
+	query.Set("trace", "on")
+	validApiUrl.RawQuery = query.Encode()
+	require.Equal(t, "trace=on&validate=true", validApiUrl.RawQuery)
+
+	graphApiUrl, err := microcksContainer.GraphQLMockEndpointURL(ctx, "Pastries Graph", "1")
+	require.NoError(t, err)
+	require.Equal(t, "/graphql/Pastries%20Graph/1", graphApiUrl.EscapedPath())
+
+	response, err := http.Get(baseApiUrl.JoinPath("pastries", "Millefeuille").String())
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	mockClient, err := microcksContainer.MockHTTPClient(ctx, "API Pastries", "0.0.1")
+	require.NoError(t, err)
+
+	response, err = mockClient.Get("/pastries?size=L")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var pastries []map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&pastries))
+	require.NotEmpty(t, pastries)
+	require.Equal(t, "L", pastries[0]["size"])
+
+	response, err = mockClient.Get("pastries/Eclair%20Cafe")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var pastry map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&pastry))
+	require.Equal(t, "Eclair Cafe", pastry["name"])
+}
+
+func TestGrpcMockConn(t *testing.T) {
+	ctx := context.Background()
+
+	microcksContainer := microcks.RunT(t, "quay.io/microcks/microcks-uber:nightly",
+		microcks.WithMainArtifact("testdata/grpc/hello-v1.proto"),
+	)
+
+	target, err := microcksContainer.GrpcMockTarget(ctx)
+	require.NoError(t, err)
+	endpoint, err := microcksContainer.GrpcMockEndpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "grpc://"+target, endpoint)
+
+	conn, err := microcksContainer.GrpcMockConn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, target, conn.Target())
+
+	services, err := microcksContainer.GrpcMockServices(ctx)
+	require.NoError(t, err)
+	require.Contains(t, services, "io.github.microcks.grpc.hello.v1.HelloService")
+}
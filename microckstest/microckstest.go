@@ -0,0 +1,252 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package microckstest provides assertions on Microcks contract tests results,
+// reporting readable diagnostics when they fail.
+package microckstest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	client "microcks.io/go-client"
+	microcks "microcks.io/testcontainers-go"
+)
+
+// Option configures the assertions.
+type Option func(*settings)
+
+type settings struct {
+	ctx       context.Context
+	container *microcks.MicrocksContainer
+}
+
+// WithMessages makes assertions fetch the request/response pairs exchanged during failing
+// test cases from container, and print them along with the failures.
+func WithMessages(ctx context.Context, container *microcks.MicrocksContainer) Option {
+	return func(s *settings) {
+		s.ctx = ctx
+		s.container = container
+	}
+}
+
+// runner is implemented by *testing.T, to run subtests.
+type runner interface {
+	Run(name string, f func(t *testing.T)) bool
+}
+
+// AssertConformance checks that the tested endpoint conforms to the contract: the test is done and
+// every test case succeeded. When t is a *testing.T, each test case is checked in a subtest named
+// after its operation, encoded with microcks.EncodeOperationName so that `go test -run` can target
+// a single operation, e.g. -run 'TestPastries/GET_!pastries$'.
+// It returns whether the assertion succeeded.
+func AssertConformance(t testing.TB, result *client.TestResult, opts ...Option) bool {
+	t.Helper()
+
+	if result == nil {
+		t.Errorf("test result is nil")
+		return false
+	}
+	s := newSettings(opts)
+
+	conform := true
+	if result.InProgress {
+		t.Errorf("test of %s against %s is still in progress", result.ServiceId, result.TestedEndpoint)
+		conform = false
+	}
+	for _, tc := range testCases(result) {
+		check := func(t testing.TB) {
+			t.Helper()
+			if !tc.Success {
+				t.Errorf("%s does not conform to %s for operation %s:\n%s",
+					result.TestedEndpoint, result.ServiceId, tc.OperationName, diagnostics(s, result, tc))
+			}
+		}
+		if run, ok := t.(runner); ok {
+			run.Run(microcks.EncodeOperationName(tc.OperationName), func(t *testing.T) { check(t) })
+		} else {
+			check(t)
+		}
+		conform = conform && tc.Success
+	}
+	if !result.Success && conform {
+		t.Errorf("test of %s against %s failed:\n%s", result.ServiceId, result.TestedEndpoint, table(testCases(result)))
+		conform = false
+	}
+	return conform
+}
+
+// RequireConformance is the same as AssertConformance but stops the test on failure.
+func RequireConformance(t testing.TB, result *client.TestResult, opts ...Option) {
+	t.Helper()
+	if !AssertConformance(t, result, opts...) {
+		t.FailNow()
+	}
+}
+
+// AssertOperationSucceeds checks that the test case of operation succeeded.
+func AssertOperationSucceeds(t testing.TB, result *client.TestResult, operation string, opts ...Option) bool {
+	t.Helper()
+
+	tc, ok := testCase(t, result, operation)
+	if !ok {
+		return false
+	}
+	if !tc.Success {
+		t.Errorf("operation %s was expected to succeed:\n%s", operation, diagnostics(newSettings(opts), result, tc))
+		return false
+	}
+	return true
+}
+
+// AssertOperationFails checks that the test case of operation failed with a step message
+// containing contains. Any failure matches an empty contains.
+func AssertOperationFails(t testing.TB, result *client.TestResult, operation string, contains string, opts ...Option) bool {
+	t.Helper()
+
+	tc, ok := testCase(t, result, operation)
+	if !ok {
+		return false
+	}
+	if tc.Success {
+		t.Errorf("operation %s was expected to fail but succeeded", operation)
+		return false
+	}
+	for _, step := range testSteps(tc) {
+		if !step.Success && strings.Contains(stepMessage(step), contains) {
+			return true
+		}
+	}
+	t.Errorf("operation %s failed without any message containing %q:\n%s", operation, contains, diagnostics(newSettings(opts), result, tc))
+	return false
+}
+
+func newSettings(opts []Option) *settings {
+	s := &settings{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func testCases(result *client.TestResult) []client.TestCaseResult {
+	if result.TestCaseResults == nil {
+		return nil
+	}
+	return *result.TestCaseResults
+}
+
+func testSteps(tc client.TestCaseResult) []client.TestStepResult {
+	if tc.TestStepResults == nil {
+		return nil
+	}
+	return *tc.TestStepResults
+}
+
+func stepName(step client.TestStepResult) string {
+	if step.RequestName != nil {
+		return *step.RequestName
+	}
+	if step.EventMessageName != nil {
+		return *step.EventMessageName
+	}
+	return "-"
+}
+
+func stepMessage(step client.TestStepResult) string {
+	if step.Message != nil {
+		return *step.Message
+	}
+	return ""
+}
+
+func testCase(t testing.TB, result *client.TestResult, operation string) (client.TestCaseResult, bool) {
+	t.Helper()
+	if result == nil {
+		t.Errorf("test result is nil")
+		return client.TestCaseResult{}, false
+	}
+	operations := make([]string, 0, len(testCases(result)))
+	for _, tc := range testCases(result) {
+		if tc.OperationName == operation {
+			return tc, true
+		}
+		operations = append(operations, tc.OperationName)
+	}
+	t.Errorf("no test case for operation %s, tested operations are: %s", operation, strings.Join(operations, ", "))
+	return client.TestCaseResult{}, false
+}
+
+// table renders the steps of test cases as a table.
+func table(testCases []client.TestCaseResult) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATION\tSTATUS\tSTEP\tMESSAGE")
+	for _, tc := range testCases {
+		steps := testSteps(tc)
+		if len(steps) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t-\t\n", tc.OperationName, status(tc.Success))
+		}
+		for _, step := range steps {
+			message := strings.ReplaceAll(stepMessage(step), "\n", " ")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tc.OperationName, status(step.Success), stepName(step), message)
+		}
+	}
+	w.Flush()
+	return b.String()
+}
+
+func status(success bool) string {
+	if success {
+		return "PASSED"
+	}
+	return "FAILED"
+}
+
+// diagnostics renders a failing test case steps and, if possible, the messages it exchanged.
+func diagnostics(s *settings, result *client.TestResult, tc client.TestCaseResult) string {
+	diag := table([]client.TestCaseResult{tc})
+	if s.container == nil {
+		return diag
+	}
+
+	messages, err := s.container.MessagesForTestCase(s.ctx, result, tc.OperationName)
+	if err != nil {
+		return diag + fmt.Sprintf("cannot retrieve exchanged messages: %v\n", err)
+	}
+	if messages == nil {
+		return diag
+	}
+
+	var b strings.Builder
+	b.WriteString(diag)
+	for _, pair := range *messages {
+		fmt.Fprintf(&b, "--- request %s\n%s\n", value(pair.Request.Name), value(pair.Request.Content))
+		fmt.Fprintf(&b, "--- response %s (status %s)\n%s\n", value(pair.Response.Name), value(pair.Response.Status), value(pair.Response.Content))
+	}
+	return b.String()
+}
+
+// value dereferences an optional string.
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
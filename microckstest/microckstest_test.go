@@ -0,0 +1,113 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microckstest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	client "microcks.io/go-client"
+	"microcks.io/testcontainers-go/microckstest"
+)
+
+// recorder is a testing.TB recording failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// subtests is a *testing.T recording the names of its subtests.
+type subtests struct {
+	*testing.T
+	names []string
+}
+
+func (s *subtests) Run(name string, f func(t *testing.T)) bool {
+	s.names = append(s.names, name)
+	return s.T.Run(name, f)
+}
+
+func testResult(t *testing.T, payload string) *client.TestResult {
+	result := &client.TestResult{}
+	require.NoError(t, json.Unmarshal([]byte(payload), result))
+	return result
+}
+
+const goodImplResult = `{
+  "id": "1", "serviceId": "API Pastries:0.0.1", "testedEndpoint": "http://good-impl:3002",
+  "success": true, "inProgress": false,
+  "testCaseResults": [
+    {"operationName": "GET /pastries", "success": true, "testStepResults": [{"success": true, "requestName": "pastries_json"}]},
+    {"operationName": "GET /pastries/{name}", "success": true, "testStepResults": [{"success": true, "requestName": "Millefeuille"}]}
+  ]
+}`
+
+const badImplResult = `{
+  "id": "2", "serviceId": "API Pastries:0.0.1", "testedEndpoint": "http://bad-impl:3001",
+  "success": false, "inProgress": false,
+  "testCaseResults": [
+    {"operationName": "GET /pastries", "success": false, "testStepResults": [
+      {"success": false, "requestName": "pastries_json", "message": "required property 'status' not found"}
+    ]},
+    {"operationName": "GET /pastries/{name}", "success": true, "testStepResults": [{"success": true, "requestName": "Millefeuille"}]}
+  ]
+}`
+
+func TestAssertConformance(t *testing.T) {
+	require.True(t, microckstest.AssertConformance(t, testResult(t, goodImplResult)))
+
+	r := &recorder{TB: t}
+	require.False(t, microckstest.AssertConformance(r, testResult(t, badImplResult)))
+	require.Len(t, r.errors, 1)
+	require.Contains(t, r.errors[0], "does not conform to API Pastries:0.0.1 for operation GET /pastries")
+	require.Contains(t, r.errors[0], "OPERATION")
+	require.Contains(t, r.errors[0], "pastries_json")
+	require.Contains(t, r.errors[0], "required property 'status' not found")
+
+	r = &recorder{TB: t}
+	require.False(t, microckstest.AssertConformance(r, nil))
+	require.Equal(t, []string{"test result is nil"}, r.errors)
+}
+
+func TestAssertConformanceSubtests(t *testing.T) {
+	// Operation names are encoded so that subtests are not nested on their slashes.
+	s := &subtests{T: t}
+	require.True(t, microckstest.AssertConformance(s, testResult(t, goodImplResult)))
+	require.Equal(t, []string{"GET !pastries", "GET !pastries!{name}"}, s.names)
+}
+
+func TestAssertOperation(t *testing.T) {
+	result := testResult(t, badImplResult)
+	require.True(t, microckstest.AssertOperationFails(t, result, "GET /pastries", "'status' not found"))
+	require.True(t, microckstest.AssertOperationSucceeds(t, result, "GET /pastries/{name}"))
+
+	r := &recorder{TB: t}
+	require.False(t, microckstest.AssertOperationFails(r, result, "GET /pastries", "timeout"))
+	require.False(t, microckstest.AssertOperationFails(r, result, "GET /pastries/{name}", ""))
+	require.False(t, microckstest.AssertOperationSucceeds(r, result, "POST /orders"))
+	require.Len(t, r.errors, 3)
+	require.Contains(t, r.errors[0], `without any message containing "timeout"`)
+	require.Contains(t, r.errors[1], "expected to fail but succeeded")
+	require.Contains(t, r.errors[2], "tested operations are: GET /pastries, GET /pastries/{name}")
+}
@@ -0,0 +1,458 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mockdsl declares REST mocks in Go: a service, its operations and their request and response
+// examples. Services are rendered as OpenAPI 3.1 documents following the Microcks conventions, and
+// imported as main artifacts.
+package mockdsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	microcks "microcks.io/testcontainers-go"
+	"microcks.io/testcontainers-go/internal/artifact"
+)
+
+// pathParamPattern matches the parameters of a path template, like {name}.
+var pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Service is a REST service declared in Go. Create it with NewService.
+type Service struct {
+	name        string
+	version     string
+	description string
+	operations  []*Operation
+}
+
+// NewService starts declaring a service with the given name and version.
+func NewService(name string, version string) *Service {
+	return &Service{name: name, version: version}
+}
+
+// Description sets the description of the service.
+func (s *Service) Description(description string) *Service {
+	s.description = description
+	return s
+}
+
+// Operation declares an operation of the service, e.g. Operation(http.MethodGet, "/pastries/{name}"),
+// or returns the one already declared.
+func (s *Service) Operation(method string, path string) *Operation {
+	method = strings.ToUpper(method)
+	for _, operation := range s.operations {
+		if operation.method == method && operation.path == path {
+			return operation
+		}
+	}
+	operation := &Operation{method: method, path: path}
+	s.operations = append(s.operations, operation)
+	return operation
+}
+
+// Ref returns the reference of the service, once imported in Microcks.
+func (s *Service) Ref() microcks.ServiceRef {
+	return microcks.ServiceRef{Name: s.name, Version: s.version}
+}
+
+// FileName returns the name of the artifact the service is rendered to.
+func (s *Service) FileName() string {
+	return artifact.FileName(s.name, s.version, "openapi")
+}
+
+// Operation is an operation of a Service, with its examples.
+type Operation struct {
+	method          string
+	path            string
+	summary         string
+	dispatcher      string
+	dispatcherRules string
+	delay           time.Duration
+	examples        []example
+}
+
+type example struct {
+	name     string
+	request  *Request
+	response *Response
+}
+
+// Summary sets the summary of the operation.
+func (o *Operation) Summary(summary string) *Operation {
+	o.summary = summary
+	return o
+}
+
+// Dispatcher sets the dispatcher, and its rules, picking the response of the operation. See the dispatch package.
+// By default, Microcks infers it from the parameters.
+func (o *Operation) Dispatcher(dispatcher string, rules string) *Operation {
+	o.dispatcher = dispatcher
+	o.dispatcherRules = rules
+	return o
+}
+
+// Delay sets the delay applied before returning responses, with a millisecond precision.
+func (o *Operation) Delay(delay time.Duration) *Operation {
+	o.delay = delay
+	return o
+}
+
+// Example adds an example: Microcks returns response when receiving a request matching req, that may be nil
+// when the operation has no parameters nor body.
+func (o *Operation) Example(name string, req *Request, resp *Response) *Operation {
+	if req == nil {
+		req = NewRequest()
+	}
+	o.examples = append(o.examples, example{name: name, request: req, response: resp})
+	return o
+}
+
+// Request is the request of an example. Create it with NewRequest.
+type Request struct {
+	pathParams  map[string]string
+	queryParams map[string]string
+	headers     map[string]string
+	body        body
+}
+
+// NewRequest starts declaring the request of an example.
+func NewRequest() *Request {
+	return &Request{pathParams: map[string]string{}, queryParams: map[string]string{}, headers: map[string]string{}}
+}
+
+// PathParam sets the value of a parameter of the operation path.
+func (r *Request) PathParam(name string, value string) *Request {
+	r.pathParams[name] = value
+	return r
+}
+
+// QueryParam sets the value of a query parameter.
+func (r *Request) QueryParam(name string, value string) *Request {
+	r.queryParams[name] = value
+	return r
+}
+
+// Header sets the value of a request header.
+func (r *Request) Header(name string, value string) *Request {
+	r.headers[name] = value
+	return r
+}
+
+// JSON sets the request body, encoded as JSON.
+func (r *Request) JSON(value any) *Request {
+	r.body = jsonBody(value)
+	return r
+}
+
+// Text sets the request body, with the text/plain media type.
+func (r *Request) Text(value string) *Request {
+	r.body = body{mediaType: "text/plain", value: value}
+	return r
+}
+
+// Response is the response of an example. Create it with NewResponse.
+type Response struct {
+	status  int
+	headers map[string]string
+	body    body
+}
+
+// NewResponse starts declaring the response of an example, with the given HTTP status.
+func NewResponse(status int) *Response {
+	return &Response{status: status, headers: map[string]string{}}
+}
+
+// Header sets the value of a response header.
+func (r *Response) Header(name string, value string) *Response {
+	r.headers[name] = value
+	return r
+}
+
+// JSON sets the response body, encoded as JSON.
+func (r *Response) JSON(value any) *Response {
+	r.body = jsonBody(value)
+	return r
+}
+
+// Text sets the response body, with the text/plain media type.
+func (r *Response) Text(value string) *Response {
+	r.body = body{mediaType: "text/plain", value: value}
+	return r
+}
+
+// body is a request or response body. An empty mediaType means no body.
+type body struct {
+	mediaType string
+	value     any
+	err       error
+}
+
+// jsonBody converts value to its JSON representation, so that it's rendered as encoding/json would.
+func jsonBody(value any) body {
+	converted, err := artifact.JSONValue(value)
+	return body{mediaType: "application/json", value: converted, err: err}
+}
+
+// Render renders the service as an OpenAPI 3.1 document, with the examples following Microcks conventions.
+// All the problems found in the declaration are reported in the error.
+func (s *Service) Render() ([]byte, error) {
+	var errs []error
+	if s.name == "" || s.version == "" {
+		errs = append(errs, errors.New("service name and version are required"))
+	}
+	if len(s.operations) == 0 {
+		errs = append(errs, errors.New("service has no operation"))
+	}
+
+	doc := document{
+		OpenAPI: "3.1.0",
+		Info:    info{Title: s.name, Version: s.version, Description: s.description},
+		Paths:   map[string]map[string]*operationObject{},
+	}
+	for _, operation := range s.operations {
+		object, err := operation.render()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", operation.method, operation.path, err))
+			continue
+		}
+		if doc.Paths[operation.path] == nil {
+			doc.Paths[operation.path] = map[string]*operationObject{}
+		}
+		doc.Paths[operation.path][strings.ToLower(operation.method)] = object
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid service %s:%s: %w", s.name, s.version, err)
+	}
+
+	return artifact.RenderYAML(doc)
+}
+
+// Import renders the service and imports it as a main artifact within the Microcks container.
+// It's then reachable through the mock endpoints, like RestMockEndpoint.
+// A zero status is returned along with the error when the service cannot be rendered.
+func (s *Service) Import(ctx context.Context, container *microcks.MicrocksContainer) (int, error) {
+	content, err := s.Render()
+	if err != nil {
+		return 0, err
+	}
+	return container.ImportArtifactFromBytes(ctx, s.FileName(), content, true)
+}
+
+func (o *Operation) render() (*operationObject, error) {
+	var errs []error
+	if !slices.Contains([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions}, o.method) {
+		errs = append(errs, fmt.Errorf("unsupported method %q", o.method))
+	}
+	if !strings.HasPrefix(o.path, "/") {
+		errs = append(errs, fmt.Errorf("path %q must start with /", o.path))
+	}
+	if len(o.examples) == 0 {
+		errs = append(errs, errors.New("operation has no example"))
+	}
+	if o.delay < 0 {
+		errs = append(errs, fmt.Errorf("delay %s is negative", o.delay))
+	}
+
+	object := &operationObject{Summary: o.summary, Responses: map[string]*responseObject{}}
+	if o.dispatcher != "" || o.delay > 0 {
+		object.Microcks = &microcksOperation{Dispatcher: o.dispatcher, DispatcherRules: o.dispatcherRules, Delay: o.delay.Milliseconds()}
+	}
+
+	var pathParams []string
+	for _, match := range pathParamPattern.FindAllStringSubmatch(o.path, -1) {
+		pathParams = append(pathParams, match[1])
+	}
+	parameters := map[string]*parameterObject{}
+	var parameterKeys []string
+	parameter := func(in string, name string) *parameterObject {
+		key := in + ":" + name
+		if parameters[key] == nil {
+			parameters[key] = &parameterObject{Name: name, In: in, Required: in == "path", Schema: stringSchema, Examples: map[string]exampleObject{}}
+			parameterKeys = append(parameterKeys, key)
+		}
+		return parameters[key]
+	}
+	for _, name := range pathParams {
+		parameter("path", name)
+	}
+
+	names := map[string]bool{}
+	for _, ex := range o.examples {
+		if ex.name == "" || names[ex.name] {
+			errs = append(errs, fmt.Errorf("example name %q is empty or duplicated", ex.name))
+			continue
+		}
+		names[ex.name] = true
+		if ex.response == nil {
+			errs = append(errs, fmt.Errorf("example %q has no response", ex.name))
+			continue
+		}
+
+		for _, name := range pathParams {
+			value, ok := ex.request.pathParams[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("example %q doesn't set path parameter %q", ex.name, name))
+				continue
+			}
+			parameter("path", name).Examples[ex.name] = exampleObject{Value: value}
+		}
+		for name := range ex.request.pathParams {
+			if !slices.Contains(pathParams, name) {
+				errs = append(errs, fmt.Errorf("example %q sets path parameter %q that is not in the path", ex.name, name))
+			}
+		}
+		for _, name := range sortedKeys(ex.request.queryParams) {
+			parameter("query", name).Examples[ex.name] = exampleObject{Value: ex.request.queryParams[name]}
+		}
+		for _, name := range sortedKeys(ex.request.headers) {
+			parameter("header", name).Examples[ex.name] = exampleObject{Value: ex.request.headers[name]}
+		}
+
+		if ex.request.body.mediaType != "" {
+			if ex.request.body.err != nil {
+				errs = append(errs, fmt.Errorf("example %q request body: %w", ex.name, ex.request.body.err))
+			}
+			if object.RequestBody == nil {
+				object.RequestBody = &requestBodyObject{Content: map[string]*mediaTypeObject{}}
+			}
+			addExample(object.RequestBody.Content, ex.request.body, ex.name)
+		}
+
+		if ex.response.status < 100 || ex.response.status > 599 {
+			errs = append(errs, fmt.Errorf("example %q has invalid status %d", ex.name, ex.response.status))
+			continue
+		}
+		status := strconv.Itoa(ex.response.status)
+		response := object.Responses[status]
+		if response == nil {
+			response = &responseObject{Description: http.StatusText(ex.response.status)}
+			object.Responses[status] = response
+		}
+		for name, value := range ex.response.headers {
+			if response.Headers == nil {
+				response.Headers = map[string]*headerObject{}
+			}
+			if response.Headers[name] == nil {
+				response.Headers[name] = &headerObject{Schema: stringSchema, Examples: map[string]exampleObject{}}
+			}
+			response.Headers[name].Examples[ex.name] = exampleObject{Value: value}
+		}
+		if ex.response.body.mediaType == "" {
+			// Responses without body are bound to their example by reference.
+			response.Refs = append(response.Refs, ex.name)
+			continue
+		}
+		if ex.response.body.err != nil {
+			errs = append(errs, fmt.Errorf("example %q response body: %w", ex.name, ex.response.body.err))
+		}
+		if response.Content == nil {
+			response.Content = map[string]*mediaTypeObject{}
+		}
+		addExample(response.Content, ex.response.body, ex.name)
+	}
+
+	for _, key := range parameterKeys {
+		object.Parameters = append(object.Parameters, parameters[key])
+	}
+	return object, errors.Join(errs...)
+}
+
+func addExample(content map[string]*mediaTypeObject, b body, name string) {
+	media := content[b.mediaType]
+	if media == nil {
+		media = &mediaTypeObject{Examples: map[string]exampleObject{}}
+		content[b.mediaType] = media
+	}
+	media.Examples[name] = exampleObject{Value: b.value}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// stringSchema is the schema of parameters and headers.
+var stringSchema = map[string]string{"type": "string"}
+
+// OpenAPI 3.1 objects, in the order they're rendered.
+type document struct {
+	OpenAPI string                                 `yaml:"openapi"`
+	Info    info                                   `yaml:"info"`
+	Paths   map[string]map[string]*operationObject `yaml:"paths"`
+}
+
+type info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type operationObject struct {
+	Summary     string                     `yaml:"summary,omitempty"`
+	Parameters  []*parameterObject         `yaml:"parameters,omitempty"`
+	RequestBody *requestBodyObject         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*responseObject `yaml:"responses"`
+	Microcks    *microcksOperation         `yaml:"x-microcks-operation,omitempty"`
+}
+
+type microcksOperation struct {
+	Dispatcher      string `yaml:"dispatcher,omitempty"`
+	DispatcherRules string `yaml:"dispatcherRules,omitempty"`
+	Delay           int64  `yaml:"delay,omitempty"`
+}
+
+type parameterObject struct {
+	Name     string                   `yaml:"name"`
+	In       string                   `yaml:"in"`
+	Required bool                     `yaml:"required"`
+	Schema   map[string]string        `yaml:"schema"`
+	Examples map[string]exampleObject `yaml:"examples,omitempty"`
+}
+
+type requestBodyObject struct {
+	Content map[string]*mediaTypeObject `yaml:"content"`
+}
+
+type responseObject struct {
+	Description string                      `yaml:"description"`
+	Headers     map[string]*headerObject    `yaml:"headers,omitempty"`
+	Content     map[string]*mediaTypeObject `yaml:"content,omitempty"`
+	Refs        []string                    `yaml:"x-microcks-refs,omitempty"`
+}
+
+type headerObject struct {
+	Schema   map[string]string        `yaml:"schema"`
+	Examples map[string]exampleObject `yaml:"examples,omitempty"`
+}
+
+type mediaTypeObject struct {
+	Examples map[string]exampleObject `yaml:"examples"`
+}
+
+type exampleObject struct {
+	Value any `yaml:"value"`
+}
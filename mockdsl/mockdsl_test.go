@@ -0,0 +1,100 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mockdsl_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"microcks.io/testcontainers-go/dispatch"
+	"microcks.io/testcontainers-go/mockdsl"
+)
+
+func pastries() *mockdsl.Service {
+	service := mockdsl.NewService("Pastries DSL", "1.0.0")
+	service.Operation(http.MethodGet, "/pastries/{name}").
+		Example("Millefeuille",
+			mockdsl.NewRequest().PathParam("name", "Millefeuille"),
+			mockdsl.NewResponse(http.StatusOK).JSON(map[string]any{"name": "Millefeuille", "price": 4.4})).
+		Example("Unknown",
+			mockdsl.NewRequest().PathParam("name", "Unknown"),
+			mockdsl.NewResponse(http.StatusNotFound).Text("Not found"))
+	service.Operation(http.MethodDelete, "/pastries/{name}").
+		Delay(100*time.Millisecond).
+		Dispatcher(dispatch.URIParts, dispatch.Params("name")).
+		Example("Deleted",
+			mockdsl.NewRequest().PathParam("name", "Millefeuille").Header("X-Token", "abc"),
+			mockdsl.NewResponse(http.StatusNoContent).Header("X-Deleted", "1"))
+	return service
+}
+
+func TestRender(t *testing.T) {
+	service := pastries()
+	require.Equal(t, "pastries-dsl-1.0.0-openapi.yaml", service.FileName())
+
+	content, err := service.Render()
+	require.NoError(t, err)
+	require.Regexp(t, "^openapi: 3.1.0\n", string(content))
+
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &doc))
+	require.Equal(t, map[string]any{"title": "Pastries DSL", "version": "1.0.0"}, doc["info"])
+
+	pathItem := doc["paths"].(map[string]any)["/pastries/{name}"].(map[string]any)
+	get := pathItem["get"].(map[string]any)
+	require.Equal(t, []any{map[string]any{
+		"name": "name", "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		"examples": map[string]any{"Millefeuille": map[string]any{"value": "Millefeuille"}, "Unknown": map[string]any{"value": "Unknown"}},
+	}}, get["parameters"])
+	responses := get["responses"].(map[string]any)
+	require.Equal(t, map[string]any{"name": "Millefeuille", "price": 4.4},
+		responses["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["examples"].(map[string]any)["Millefeuille"].(map[string]any)["value"])
+	require.Equal(t, "Not found",
+		responses["404"].(map[string]any)["content"].(map[string]any)["text/plain"].(map[string]any)["examples"].(map[string]any)["Unknown"].(map[string]any)["value"])
+
+	del := pathItem["delete"].(map[string]any)
+	require.Equal(t, map[string]any{"dispatcher": "URI_PARTS", "dispatcherRules": "name", "delay": 100}, del["x-microcks-operation"])
+	noContent := del["responses"].(map[string]any)["204"].(map[string]any)
+	require.Equal(t, []any{"Deleted"}, noContent["x-microcks-refs"])
+	require.Contains(t, noContent["headers"], "X-Deleted")
+}
+
+func TestRenderValidation(t *testing.T) {
+	_, err := mockdsl.NewService("Empty", "").Render()
+	require.ErrorContains(t, err, "service name and version are required")
+	require.ErrorContains(t, err, "service has no operation")
+
+	service := mockdsl.NewService("Invalid", "1.0")
+	service.Operation("FETCH", "pastries/{name}").
+		Example("Missing param", nil, mockdsl.NewResponse(http.StatusOK)).
+		Example("Missing param", mockdsl.NewRequest().PathParam("name", "a").PathParam("id", "1"), mockdsl.NewResponse(1000)).
+		Example("Bad body", mockdsl.NewRequest().PathParam("name", "b").JSON(func() {}), nil)
+	_, err = service.Render()
+	require.ErrorContains(t, err, `unsupported method "FETCH"`)
+	require.ErrorContains(t, err, `path "pastries/{name}" must start with /`)
+	require.ErrorContains(t, err, `example "Missing param" doesn't set path parameter "name"`)
+	require.ErrorContains(t, err, `example name "Missing param" is empty or duplicated`)
+	require.ErrorContains(t, err, `example "Bad body" has no response`)
+
+	// Invalid services are not sent to Microcks.
+	status, err := service.Import(context.Background(), nil)
+	require.ErrorContains(t, err, `unsupported method "FETCH"`)
+	require.Zero(t, status)
+}
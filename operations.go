@@ -0,0 +1,113 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// ErrOperationNotFound is returned when an operation is not found in a Microcks service. Use errors.Is to check it.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// RestoreFunc restores what has been changed at runtime. Once it succeeds, further calls do nothing, so that
+// a failed restoration can be retried.
+type RestoreFunc func(ctx context.Context) error
+
+// Cleanup restores at the end of test t, and fails it if restoring fails.
+func (restore RestoreFunc) Cleanup(t testing.TB) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := restore(context.Background()); err != nil {
+			t.Errorf("cannot restore: %v", err)
+		}
+	})
+}
+
+// operationOverride holds the settings of a mocked operation that can be changed at runtime.
+type operationOverride struct {
+	Dispatcher      string `json:"dispatcher"`
+	DispatcherRules string `json:"dispatcherRules"`
+	DefaultDelay    int64  `json:"defaultDelay"`
+}
+
+// SetOperationDispatcher changes the dispatcher, and its rules, picking the responses of a mocked operation.
+// See the dispatch package for dispatchers and rules builders. The returned RestoreFunc restores the original
+// dispatcher; register it with Cleanup to restore at the end of a test.
+// It returns an error wrapping ErrServiceNotFound or ErrOperationNotFound if there's no such operation.
+func (container *MicrocksContainer) SetOperationDispatcher(ctx context.Context, serviceName string, serviceVersion string, operationName string, dispatcher string, rules string) (RestoreFunc, error) {
+	original, err := container.updateOperation(ctx, serviceName, serviceVersion, operationName, func(o *operationOverride) {
+		o.Dispatcher, o.DispatcherRules = dispatcher, rules
+	})
+	if err != nil {
+		return nil, err
+	}
+	return container.restoreOperation(serviceName, serviceVersion, operationName, func(o *operationOverride) {
+		o.Dispatcher, o.DispatcherRules = original.Dispatcher, original.DispatcherRules
+	}), nil
+}
+
+// updateOperation applies change to the current settings of an operation, and returns these original settings.
+func (container *MicrocksContainer) updateOperation(ctx context.Context, serviceName string, serviceVersion string, operationName string, change func(*operationOverride)) (operationOverride, error) {
+	service, err := container.GetService(ctx, serviceName, serviceVersion)
+	if err != nil {
+		return operationOverride{}, err
+	}
+	operation, ok := service.Operation(operationName)
+	if !ok {
+		return operationOverride{}, fmt.Errorf("%s on %s:%s: %w", operationName, serviceName, serviceVersion, ErrOperationNotFound)
+	}
+
+	original := operationOverride{
+		Dispatcher:      operation.Dispatcher,
+		DispatcherRules: operation.DispatcherRules,
+		DefaultDelay:    operation.DefaultDelay,
+	}
+	override := original
+	change(&override)
+
+	query := url.Values{"operationName": {operationName}}
+	if _, err := container.callAPI(ctx, OperationUpdateOperation, http.MethodPut, "/services/"+url.PathEscape(service.Id)+"/operation", query, override, nil); err != nil {
+		return operationOverride{}, err
+	}
+	return original, nil
+}
+
+// restoreOperation returns a RestoreFunc applying change to the then current settings of an operation,
+// so that restoring a setting keeps the others.
+func (container *MicrocksContainer) restoreOperation(serviceName string, serviceVersion string, operationName string, change func(*operationOverride)) RestoreFunc {
+	var mu sync.Mutex
+	restored := false
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if restored {
+			return nil
+		}
+		if _, err := container.updateOperation(ctx, serviceName, serviceVersion, operationName, change); err != nil {
+			return err
+		}
+		restored = true
+		return nil
+	}
+}
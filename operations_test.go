@@ -0,0 +1,65 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreRetriesUntilSuccess(t *testing.T) {
+	service := Service{Id: "1", Name: "API Pastries", Version: "0.0.1", Operations: []Operation{{Name: "GET /pastries"}}}
+	updates := &atomic.Int64{}
+	container := newStubContainer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/services":
+			if err := json.NewEncoder(w).Encode([]Service{service}); err != nil {
+				t.Errorf("cannot encode services: %v", err)
+				return
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/api/services/1":
+			if err := json.NewEncoder(w).Encode(service); err != nil {
+				t.Errorf("cannot encode service: %v", err)
+				return
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/api/services/1/operation":
+			// The first restoration fails.
+			if updates.Add(1) == 2 {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	ctx := context.Background()
+
+	restore, err := container.SetOperationDispatcher(ctx, "API Pastries", "0.0.1", "GET /pastries", "RANDOM", "")
+	require.NoError(t, err)
+
+	var apiErr *APIError
+	require.ErrorAs(t, restore(ctx), &apiErr)
+	require.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+
+	// A failed restoration is retried, a successful one is not.
+	require.NoError(t, restore(ctx))
+	require.NoError(t, restore(ctx))
+	require.EqualValues(t, 3, updates.Load())
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// maxConcurrentImports bounds the number of steps of a same phase run at the same time.
+const maxConcurrentImports = 4
+
+// importPhase orders the steps of the import plan. Phases run one after the other,
+// steps within a phase are independent and run concurrently.
+type importPhase int
+
+const (
+	phaseSecrets importPhase = iota
+	phaseSnapshots
+	phaseMainArtifacts
+	phaseSecondaryArtifacts
+	phaseMainRemoteArtifacts
+	phaseSecondaryRemoteArtifacts
+	phaseSnapshotFixtures
+	phaseWebhooks
+	phaseCount
+)
+
+// postReadyAction is an action run against the MicrocksContainer once it's ready.
+type postReadyAction func(ctx context.Context, container *MicrocksContainer) error
+
+// importStep is a single action of the import plan.
+type importStep struct {
+	// description names what the step imports, for error reporting.
+	description string
+	run         postReadyAction
+}
+
+// importPlan gathers what options ask to import once the container is ready.
+type importPlan struct {
+	phases [phaseCount][]importStep
+}
+
+// add appends a step to a phase of the plan.
+func (p *importPlan) add(phase importPhase, description string, run postReadyAction) {
+	p.phases[phase] = append(p.phases[phase], importStep{description: description, run: run})
+}
+
+// empty tells if the plan has nothing to do.
+func (p *importPlan) empty() bool {
+	for _, steps := range p.phases {
+		if len(steps) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// execute runs all the phases in order. A failing step doesn't prevent the others
+// from running: all failures are reported in a single joined error.
+func (p *importPlan) execute(ctx context.Context, container *MicrocksContainer) error {
+	var errs []error
+	for _, steps := range p.phases {
+		errs = append(errs, runConcurrently(ctx, container, steps)...)
+	}
+	return errors.Join(errs...)
+}
+
+// runConcurrently runs steps with bounded concurrency and returns their errors, in steps order.
+func runConcurrently(ctx context.Context, container *MicrocksContainer, steps []importStep) []error {
+	errs := make([]error, len(steps))
+	semaphore := make(chan struct{}, maxConcurrentImports)
+
+	var wg sync.WaitGroup
+	for i, step := range steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if err := step.run(ctx, container); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", step.description, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff configures the delays between two polls of the Microcks API.
+type Backoff struct {
+	// Initial is the delay before the second poll.
+	Initial time.Duration
+	// Max caps the delay between two polls.
+	Max time.Duration
+	// Multiplier grows the delay after each poll. Values below 1 keep it constant.
+	Multiplier float64
+}
+
+// DefaultBackoff starts polling quickly and slows down up to one poll per second.
+var DefaultBackoff = Backoff{
+	Initial:    50 * time.Millisecond,
+	Max:        time.Second,
+	Multiplier: 2,
+}
+
+// backoff returns the backoff used when polling the Microcks API.
+func (container *MicrocksContainer) backoff() Backoff {
+	if container.pollingBackoff != nil {
+		return *container.pollingBackoff
+	}
+	return DefaultBackoff
+}
+
+// next returns the delay following delay.
+func (b Backoff) next(delay time.Duration) time.Duration {
+	if b.Multiplier > 1 {
+		delay = time.Duration(float64(delay) * b.Multiplier)
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// poll calls check until it reports done, it fails or ctx is done. In the latter case, ctx error is returned.
+func poll(ctx context.Context, backoff Backoff, check func(ctx context.Context) (bool, error)) error {
+	delay := backoff.Initial
+	if delay <= 0 {
+		delay = DefaultBackoff.Initial
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		done, err := check(ctx)
+		if err != nil || done {
+			return err
+		}
+
+		timer.Reset(delay)
+		delay = backoff.next(delay)
+	}
+}
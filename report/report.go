@@ -0,0 +1,302 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package report exports Microcks contract tests results in formats CI tools understand:
+// JUnit XML and a stable JSON summary.
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	client "microcks.io/go-client"
+)
+
+// SummaryVersion is the version of the JSON summary format.
+const SummaryVersion = 1
+
+// Summary is the JSON summary of contract tests results. It only holds values that don't change
+// between two runs of the same tests, so that summaries can be archived and compared.
+type Summary struct {
+	Version int             `json:"version"`
+	Success bool            `json:"success"`
+	Results []ResultSummary `json:"results"`
+}
+
+// ResultSummary summarizes a contract test result.
+type ResultSummary struct {
+	ServiceId      string            `json:"serviceId"`
+	TestedEndpoint string            `json:"testedEndpoint"`
+	RunnerType     string            `json:"runnerType"`
+	Success        bool              `json:"success"`
+	InProgress     bool              `json:"inProgress,omitempty"`
+	TestCases      []TestCaseSummary `json:"testCases"`
+}
+
+// TestCaseSummary summarizes the test of an operation.
+type TestCaseSummary struct {
+	Operation string   `json:"operation"`
+	Success   bool     `json:"success"`
+	Failures  []string `json:"failures,omitempty"`
+}
+
+// failures returns the messages of the failing steps of a test case.
+// A failing test case without any message gets a generic one.
+func failures(tc client.TestCaseResult) []string {
+	if tc.Success {
+		return nil
+	}
+	var failures []string
+	if tc.TestStepResults != nil {
+		for _, step := range *tc.TestStepResults {
+			if step.Success {
+				continue
+			}
+			message := "step failed"
+			if step.Message != nil && *step.Message != "" {
+				message = *step.Message
+			}
+			if name := stepName(step); name != "" {
+				message = name + ": " + message
+			}
+			failures = append(failures, message)
+		}
+	}
+	if len(failures) == 0 {
+		failures = append(failures, "operation "+tc.OperationName+" failed")
+	}
+	return failures
+}
+
+func stepName(step client.TestStepResult) string {
+	if step.RequestName != nil {
+		return *step.RequestName
+	}
+	if step.EventMessageName != nil {
+		return *step.EventMessageName
+	}
+	return ""
+}
+
+// testCaseResults returns the test case results of a test result, if any.
+func testCaseResults(result *client.TestResult) []client.TestCaseResult {
+	if result.TestCaseResults == nil {
+		return nil
+	}
+	return *result.TestCaseResults
+}
+
+// elapsedTime returns the elapsed time of a test result in milliseconds, 0 if unknown.
+func elapsedTime(result *client.TestResult) float64 {
+	if result.ElapsedTime == nil {
+		return 0
+	}
+	return float64(*result.ElapsedTime)
+}
+
+// checkResults fails if any of the test results is nil.
+func checkResults(results []*client.TestResult) error {
+	for _, result := range results {
+		if result == nil {
+			return errors.New("test result is nil")
+		}
+	}
+	return nil
+}
+
+// Summarize builds the JSON summary of test results. Test cases are sorted by operation name.
+func Summarize(results ...*client.TestResult) (*Summary, error) {
+	if err := checkResults(results); err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{Version: SummaryVersion, Success: true, Results: []ResultSummary{}}
+	for _, result := range results {
+		resultSummary := ResultSummary{
+			ServiceId:      result.ServiceId,
+			TestedEndpoint: result.TestedEndpoint,
+			RunnerType:     string(result.RunnerType),
+			Success:        result.Success,
+			InProgress:     result.InProgress,
+			TestCases:      []TestCaseSummary{},
+		}
+		for _, tc := range testCaseResults(result) {
+			resultSummary.TestCases = append(resultSummary.TestCases, TestCaseSummary{
+				Operation: tc.OperationName,
+				Success:   tc.Success,
+				Failures:  failures(tc),
+			})
+		}
+		sort.SliceStable(resultSummary.TestCases, func(i, j int) bool {
+			return resultSummary.TestCases[i].Operation < resultSummary.TestCases[j].Operation
+		})
+
+		summary.Success = summary.Success && result.Success && !result.InProgress
+		summary.Results = append(summary.Results, resultSummary)
+	}
+	return summary, nil
+}
+
+// WriteJSON writes the JSON summary of test results to w.
+func WriteJSON(w io.Writer, results ...*client.TestResult) error {
+	summary, err := Summarize(results...)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitFailure   `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes test results as JUnit XML to w. Each test result is a test suite named after
+// the tested service, and each test case result a test case named after its operation. A failing test
+// case gets a single failure joining the messages of its failing steps. While a test is in progress,
+// its test cases not successful yet are reported as failed, as is the test itself when it has no test
+// case result yet. Timings and the tested endpoint are reported as properties.
+func WriteJUnit(w io.Writer, results ...*client.TestResult) error {
+	if err := checkResults(results); err != nil {
+		return err
+	}
+
+	suites := junitTestSuites{}
+	var totalTime float64
+	for _, result := range results {
+		runnerType := string(result.RunnerType)
+		suite := junitTestSuite{
+			Name: result.ServiceId,
+			Time: seconds(elapsedTime(result)),
+			Properties: []junitProperty{
+				{Name: "testResultId", Value: result.Id},
+				{Name: "testNumber", Value: strconv.FormatFloat(float64(result.TestNumber), 'f', -1, 64)},
+				{Name: "testedEndpoint", Value: result.TestedEndpoint},
+				{Name: "runnerType", Value: runnerType},
+				{Name: "elapsedTime", Value: milliseconds(elapsedTime(result))},
+				{Name: "inProgress", Value: strconv.FormatBool(result.InProgress)},
+			},
+		}
+		if result.TestDate > 0 {
+			suite.Timestamp = time.UnixMilli(int64(result.TestDate)).UTC().Format("2006-01-02T15:04:05")
+		}
+
+		for _, tc := range testCaseResults(result) {
+			testCase := junitTestCase{
+				Name:      tc.OperationName,
+				ClassName: result.ServiceId,
+				Time:      seconds(float64(tc.ElapsedTime)),
+				Properties: []junitProperty{
+					{Name: "testedEndpoint", Value: result.TestedEndpoint},
+					{Name: "elapsedTime", Value: milliseconds(float64(tc.ElapsedTime))},
+				},
+			}
+			switch {
+			case tc.Success:
+			case result.InProgress:
+				testCase.Failure = inProgressFailure()
+			default:
+				messages := failures(tc)
+				testCase.Failure = &junitFailure{Message: strings.Join(messages, "; "), Type: runnerType, Text: strings.Join(messages, "\n")}
+			}
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		// A test still in progress without any test case result yet must not pass.
+		if result.InProgress && len(suite.TestCases) == 0 {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      result.ServiceId,
+				ClassName: result.ServiceId,
+				Time:      seconds(0),
+				Failure:   inProgressFailure(),
+			})
+			suite.Failures++
+		}
+		suite.Tests = len(suite.TestCases)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		totalTime += elapsedTime(result)
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = seconds(totalTime)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func inProgressFailure() *junitFailure {
+	return &junitFailure{Message: "test still in progress", Type: "inProgress", Text: "test still in progress"}
+}
+
+// seconds formats a duration in milliseconds as JUnit expects it.
+func seconds(ms float64) string {
+	return strconv.FormatFloat(ms/1000, 'f', 3, 64)
+}
+
+func milliseconds(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', -1, 64) + "ms"
+}
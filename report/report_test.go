@@ -0,0 +1,147 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	client "microcks.io/go-client"
+	"microcks.io/testcontainers-go/report"
+)
+
+const badImplResult = `{
+  "id": "6789",
+  "testNumber": 2,
+  "testDate": 1700000000000,
+  "serviceId": "API Pastries:0.0.1",
+  "testedEndpoint": "http://bad-impl:3001",
+  "runnerType": "OPEN_API_SCHEMA",
+  "success": false,
+  "inProgress": false,
+  "elapsedTime": 1250,
+  "testCaseResults": [
+    {
+      "operationName": "GET /pastry/{name}",
+      "success": true,
+      "elapsedTime": 250,
+      "testStepResults": [{"success": true, "elapsedTime": 250, "requestName": "Millefeuille"}]
+    },
+    {
+      "operationName": "GET /pastries",
+      "success": false,
+      "elapsedTime": 1000,
+      "testStepResults": [
+        {"success": false, "elapsedTime": 250, "requestName": "pastries_json", "message": "string found, number expected"},
+        {"success": false, "elapsedTime": 250, "requestName": "pastries_empty", "message": "response is empty"},
+        {"success": true, "elapsedTime": 500, "requestName": "pastries_xml"}
+      ]
+    }
+  ]
+}`
+
+func badImplTestResult(t *testing.T) *client.TestResult {
+	result := &client.TestResult{}
+	require.NoError(t, json.Unmarshal([]byte(badImplResult), result))
+	return result
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&out, badImplTestResult(t)))
+
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name       string `xml:"name,attr"`
+			Properties []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value,attr"`
+			} `xml:"properties>property"`
+			TestCases []struct {
+				Name     string `xml:"name,attr"`
+				Time     string `xml:"time,attr"`
+				Failures []struct {
+					Message string `xml:"message,attr"`
+					Type    string `xml:"type,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &suites))
+	require.Equal(t, 2, suites.Tests)
+	require.Equal(t, 1, suites.Failures)
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	require.Equal(t, "API Pastries:0.0.1", suite.Name)
+	require.Contains(t, suite.Properties, struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	}{"testedEndpoint", "http://bad-impl:3001"})
+
+	require.Len(t, suite.TestCases, 2)
+	require.Equal(t, "GET /pastry/{name}", suite.TestCases[0].Name)
+	require.Equal(t, "0.250", suite.TestCases[0].Time)
+	require.Empty(t, suite.TestCases[0].Failures)
+	require.Equal(t, "GET /pastries", suite.TestCases[1].Name)
+	require.Len(t, suite.TestCases[1].Failures, 1)
+	require.Equal(t, "pastries_json: string found, number expected; pastries_empty: response is empty", suite.TestCases[1].Failures[0].Message)
+
+	// Test cases not successful yet are failed while the test is in progress.
+	inProgress := badImplTestResult(t)
+	inProgress.InProgress = true
+	out.Reset()
+	suites.Suites = nil
+	require.NoError(t, report.WriteJUnit(&out, inProgress, &client.TestResult{ServiceId: "API Cookies:0.0.1", InProgress: true}))
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &suites))
+	require.Equal(t, 3, suites.Tests)
+	require.Equal(t, 2, suites.Failures)
+	require.Len(t, suites.Suites, 2)
+	require.Empty(t, suites.Suites[0].TestCases[0].Failures)
+	require.Len(t, suites.Suites[0].TestCases[1].Failures, 1)
+	require.Equal(t, "inProgress", suites.Suites[0].TestCases[1].Failures[0].Type)
+	require.Len(t, suites.Suites[1].TestCases, 1)
+	require.Equal(t, "test still in progress", suites.Suites[1].TestCases[0].Failures[0].Message)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJSON(&out, badImplTestResult(t)))
+
+	// Summary is stable: no identifier, date or timing, and test cases sorted by operation.
+	require.JSONEq(t, `{
+	  "version": 1,
+	  "success": false,
+	  "results": [{
+	    "serviceId": "API Pastries:0.0.1",
+	    "testedEndpoint": "http://bad-impl:3001",
+	    "runnerType": "OPEN_API_SCHEMA",
+	    "success": false,
+	    "testCases": [
+	      {"operation": "GET /pastries", "success": false, "failures": ["pastries_json: string found, number expected", "pastries_empty: response is empty"]},
+	      {"operation": "GET /pastry/{name}", "success": true}
+	    ]
+	  }]
+	}`, out.String())
+
+	_, err := report.Summarize(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResetReport tells what Reset removed from Microcks.
+type ResetReport struct {
+	// Services are the deleted services.
+	Services []ServiceRef
+	// Secrets are the names of the deleted secrets.
+	Secrets []string
+	// Webhooks are the identifiers of the unregistered webhooks.
+	Webhooks []string
+}
+
+// Reset puts a running Microcks container back into a known state, so that it can be shared between tests.
+// It deletes every service but the kept ones, unregisters the webhooks of deleted services and deletes
+// the secrets, except those provided with WithSecret at startup.
+// Test results are kept for kept services, as Microcks API doesn't allow deleting them: Microcks only deletes
+// the test results of a service along with the service itself.
+// Removal goes on when an item cannot be removed: all failures are reported in the error,
+// along with the report of what has been removed.
+func (container *MicrocksContainer) Reset(ctx context.Context, keep ...ServiceRef) (*ResetReport, error) {
+	report := &ResetReport{}
+	var errs []error
+
+	services, err := container.ListServices(ctx, ServiceFilter{})
+	if err != nil {
+		return report, err
+	}
+
+	// Webhooks first, as they reference services operations.
+	var keptServiceIds []string
+	for _, service := range services {
+		if slices.Contains(keep, service.Ref()) {
+			keptServiceIds = append(keptServiceIds, service.Id)
+		}
+	}
+	webhooks, err := container.ListWebhooks(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, webhook := range webhooks {
+		if slices.ContainsFunc(keptServiceIds, func(id string) bool { return strings.HasPrefix(webhook.OperationId, id+"-") }) {
+			continue
+		}
+		if err := container.UnregisterWebhook(ctx, webhook.Id); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", webhook.Id, err))
+			continue
+		}
+		report.Webhooks = append(report.Webhooks, webhook.Id)
+	}
+
+	for _, service := range services {
+		if slices.Contains(keep, service.Ref()) {
+			continue
+		}
+		if err := container.deleteService(ctx, service.Id); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", service.Ref(), err))
+			continue
+		}
+		report.Services = append(report.Services, service.Ref())
+	}
+
+	secrets, err := container.listSecretEntries(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, secret := range secrets {
+		if slices.Contains(container.startupSecrets, secret.Name) {
+			continue
+		}
+		if err := container.deleteSecret(ctx, secret.Id); err != nil {
+			errs = append(errs, fmt.Errorf("secret %s: %w", secret.Name, err))
+			continue
+		}
+		report.Secrets = append(report.Secrets, secret.Name)
+	}
+
+	return report, errors.Join(errs...)
+}
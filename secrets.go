@@ -0,0 +1,110 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	client "microcks.io/go-client"
+)
+
+// ErrSecretNotFound is returned when a secret is not found in Microcks. Use errors.Is to check it.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretEntry is the subset of a Microcks secret needed to manage it.
+type secretEntry struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListSecrets lists the secrets known by Microcks.
+func (container *MicrocksContainer) ListSecrets(ctx context.Context) ([]client.Secret, error) {
+	var secrets []client.Secret
+	if _, err := container.callAPI(ctx, OperationListSecrets, http.MethodGet, "/secrets", nil, nil, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// FindSecretByName finds a secret by its name.
+// It returns an error wrapping ErrSecretNotFound if there's no such secret.
+func (container *MicrocksContainer) FindSecretByName(ctx context.Context, name string) (*client.Secret, error) {
+	secrets, err := container.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range secrets {
+		if secrets[i].Name == name {
+			return &secrets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+}
+
+// UpdateSecret replaces the content of the secret having the same name as s.
+// Any identifier set in s is replaced by the one of the existing secret.
+// It returns an error wrapping ErrSecretNotFound if there's no such secret.
+func (container *MicrocksContainer) UpdateSecret(ctx context.Context, s client.Secret) error {
+	secretId, err := container.secretId(ctx, s.Name)
+	if err != nil {
+		return err
+	}
+	s.Id = &secretId
+	_, err = container.callAPI(ctx, OperationUpdateSecret, http.MethodPut, "/secrets/"+url.PathEscape(secretId), nil, s, nil)
+	return err
+}
+
+// DeleteSecret deletes a secret, identified by its name.
+// It returns an error wrapping ErrSecretNotFound if there's no such secret.
+func (container *MicrocksContainer) DeleteSecret(ctx context.Context, name string) error {
+	secretId, err := container.secretId(ctx, name)
+	if err != nil {
+		return err
+	}
+	return container.deleteSecret(ctx, secretId)
+}
+
+func (container *MicrocksContainer) deleteSecret(ctx context.Context, secretId string) error {
+	_, err := container.callAPI(ctx, OperationDeleteSecret, http.MethodDelete, "/secrets/"+url.PathEscape(secretId), nil, nil, nil)
+	return err
+}
+
+// secretId finds the Microcks identifier of a secret from its name.
+func (container *MicrocksContainer) secretId(ctx context.Context, name string) (string, error) {
+	secrets, err := container.listSecretEntries(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, secret := range secrets {
+		if secret.Name == name {
+			return secret.Id, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+}
+
+// listSecretEntries lists all the secrets known by Microcks.
+func (container *MicrocksContainer) listSecretEntries(ctx context.Context) ([]secretEntry, error) {
+	var secrets []secretEntry
+	if _, err := container.callAPI(ctx, OperationListSecrets, http.MethodGet, "/secrets", nil, nil, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
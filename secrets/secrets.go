@@ -0,0 +1,162 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package secrets builds Microcks secrets, filling and validating the right fields
+// for each kind of authentication.
+package secrets
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	client "microcks.io/go-client"
+)
+
+// Builder builds a client.Secret. Create it with New, then set its credentials
+// and, optionally, a CA certificate.
+type Builder struct {
+	name        string
+	description string
+	username    string
+	password    string
+	token       string
+	tokenHeader string
+	caCertPem   string
+	errs        []error
+}
+
+// New starts building a secret with the given name.
+func New(name string) *Builder {
+	return &Builder{name: name}
+}
+
+// Description sets the description of the secret.
+func (b *Builder) Description(description string) *Builder {
+	b.description = description
+	return b
+}
+
+// BasicAuth sets username and password credentials.
+func (b *Builder) BasicAuth(username string, password string) *Builder {
+	b.username = username
+	b.password = password
+	return b
+}
+
+// Token sets a token credential, sent in header. The Authorization header with
+// the Bearer scheme is used when header is empty.
+func (b *Builder) Token(header string, value string) *Builder {
+	b.tokenHeader = header
+	b.token = value
+	return b
+}
+
+// CACert sets the PEM encoded certificate of the authority to trust when connecting.
+func (b *Builder) CACert(pemCert []byte) *Builder {
+	b.caCertPem = string(pemCert)
+	return b
+}
+
+// CACertFromFile reads the PEM encoded certificate of the authority to trust when connecting from path.
+func (b *Builder) CACertFromFile(path string) *Builder {
+	pemCert, err := os.ReadFile(path)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("cannot read CA certificate: %w", err))
+		return b
+	}
+	return b.CACert(pemCert)
+}
+
+// Build validates the secret and returns it. All the problems found are reported in the error.
+func (b *Builder) Build() (client.Secret, error) {
+	if err := b.validate(); err != nil {
+		return client.Secret{}, fmt.Errorf("invalid secret %q: %w", b.name, err)
+	}
+
+	secret := client.Secret{Name: b.name, Description: b.description}
+	if b.username != "" {
+		secret.Username = optional(b.username)
+		secret.Password = optional(b.password)
+	}
+	secret.Token = optional(b.token)
+	secret.TokenHeader = optional(b.tokenHeader)
+	secret.CaCertPem = optional(b.caCertPem)
+	return secret, nil
+}
+
+func (b *Builder) validate() error {
+	errs := append([]error{}, b.errs...)
+	if b.name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	basicAuth := b.username != "" || b.password != ""
+	tokenAuth := b.token != "" || b.tokenHeader != ""
+	if basicAuth && (b.username == "" || b.password == "") {
+		errs = append(errs, errors.New("basic authentication requires both username and password"))
+	}
+	if tokenAuth && b.token == "" {
+		errs = append(errs, errors.New("token value is required"))
+	}
+	if basicAuth && tokenAuth {
+		errs = append(errs, errors.New("basic authentication and token cannot be combined"))
+	}
+	if b.caCertPem != "" {
+		if block, _ := pem.Decode([]byte(b.caCertPem)); block == nil || block.Type != "CERTIFICATE" {
+			errs = append(errs, errors.New("CA certificate is not a PEM encoded certificate"))
+		}
+	}
+	if !basicAuth && !tokenAuth && b.caCertPem == "" && len(b.errs) == 0 {
+		errs = append(errs, errors.New("credentials or CA certificate are required"))
+	}
+	return errors.Join(errs...)
+}
+
+// optional returns a pointer to value, or nil if value is empty.
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// BasicAuth builds a secret holding username and password credentials.
+func BasicAuth(name string, username string, password string) (client.Secret, error) {
+	return New(name).BasicAuth(username, password).Build()
+}
+
+// Token builds a secret holding a token credential, sent in header.
+// The Authorization header with the Bearer scheme is used when header is empty.
+func Token(name string, header string, value string) (client.Secret, error) {
+	return New(name).Token(header, value).Build()
+}
+
+// CACertFromFile builds a secret holding the PEM encoded certificate, read from path,
+// of the authority to trust when connecting.
+func CACertFromFile(name string, path string) (client.Secret, error) {
+	return New(name).CACertFromFile(path).Build()
+}
+
+// Must returns the secret or panics if err is not nil. It eases the use of builders
+// when providing options to microcks.Run.
+func Must(secret client.Secret, err error) client.Secret {
+	if err != nil {
+		panic(err)
+	}
+	return secret
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package secrets_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	client "microcks.io/go-client"
+	"microcks.io/testcontainers-go/secrets"
+)
+
+// fields returns the non-empty fields of a secret, as sent to Microcks.
+func fields(t *testing.T, secret client.Secret) map[string]string {
+	payload, err := json.Marshal(secret)
+	require.NoError(t, err)
+	var all map[string]any
+	require.NoError(t, json.Unmarshal(payload, &all))
+
+	fields := map[string]string{}
+	for key, value := range all {
+		if s, ok := value.(string); ok && s != "" {
+			fields[key] = s
+		}
+	}
+	return fields
+}
+
+func TestBuilders(t *testing.T) {
+	secret, err := secrets.BasicAuth("my-basic", "admin", "s3cr3t")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"name": "my-basic", "username": "admin", "password": "s3cr3t"}, fields(t, secret))
+
+	secret, err = secrets.Token("my-token", "X-Api-Key", "abc")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"name": "my-token", "token": "abc", "tokenHeader": "X-Api-Key"}, fields(t, secret))
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not really a certificate")})
+	caCertPath := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caCertPath, caCert, 0o600))
+
+	secret, err = secrets.CACertFromFile("my-ca", caCertPath)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"name": "my-ca", "caCertPem": string(caCert)}, fields(t, secret))
+
+	secret, err = secrets.New("my-mtls").Token("", "abc").CACert(caCert).Build()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"name": "my-mtls", "token": "abc", "caCertPem": string(caCert)}, fields(t, secret))
+}
+
+func TestBuildersValidation(t *testing.T) {
+	_, err := secrets.BasicAuth("my-basic", "admin", "")
+	require.ErrorContains(t, err, "requires both username and password")
+
+	_, err = secrets.Token("", "X-Api-Key", "")
+	require.ErrorContains(t, err, "name is required")
+	require.ErrorContains(t, err, "token value is required")
+
+	_, err = secrets.New("my-secret").BasicAuth("admin", "s3cr3t").Token("", "abc").Build()
+	require.ErrorContains(t, err, "cannot be combined")
+
+	_, err = secrets.New("my-secret").CACert([]byte("-----BEGIN KEY-----")).Build()
+	require.ErrorContains(t, err, "not a PEM encoded certificate")
+
+	_, err = secrets.CACertFromFile("my-ca", filepath.Join(t.TempDir(), "missing.pem"))
+	require.ErrorContains(t, err, "cannot read CA certificate")
+
+	_, err = secrets.New("my-secret").Build()
+	require.ErrorContains(t, err, "credentials or CA certificate are required")
+
+	require.Panics(t, func() { secrets.Must(secrets.BasicAuth("my-basic", "", "")) })
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// servicesPageSize is the number of services retrieved per page when listing services.
+const servicesPageSize = 100
+
+// ErrServiceNotFound is returned when a service is not found in Microcks. Use errors.Is to check it.
+var ErrServiceNotFound = errors.New("service not found")
+
+// ServiceRef identifies a service by its name and version.
+type ServiceRef struct {
+	Name    string
+	Version string
+}
+
+// ParseServiceRef parses a service identifier in the "name:version" format used by Microcks.
+func ParseServiceRef(serviceId string) (ServiceRef, error) {
+	name, version, ok := strings.Cut(serviceId, ":")
+	if !ok || name == "" || version == "" {
+		return ServiceRef{}, fmt.Errorf("invalid serviceId format, expected 'name:version', got %q", serviceId)
+	}
+	return ServiceRef{Name: name, Version: version}, nil
+}
+
+// String returns the service identifier in the "name:version" format used by Microcks.
+func (r ServiceRef) String() string {
+	return r.Name + ":" + r.Version
+}
+
+// Service is a service - an API - imported in Microcks.
+type Service struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Type is the service type, e.g. "REST", "SOAP_HTTP", "GRAPHQL", "GRPC" or "EVENT".
+	Type string `json:"type"`
+	// SourceArtifact is the name of the main artifact the service has been imported from.
+	SourceArtifact string          `json:"sourceArtifact"`
+	Metadata       ServiceMetadata `json:"metadata"`
+	Operations     []Operation     `json:"operations"`
+}
+
+// ServiceMetadata holds the labels and annotations of a service.
+type ServiceMetadata struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Operation is an operation of a service, as mocked by Microcks.
+type Operation struct {
+	// Name is the operation name, e.g. "GET /pastries/{name}".
+	Name       string `json:"name"`
+	Method     string `json:"method"`
+	InputName  string `json:"inputName"`
+	OutputName string `json:"outputName"`
+	// Dispatcher is the dispatcher used to pick the response to return, e.g. "URI_PARTS" or "SCRIPT".
+	Dispatcher string `json:"dispatcher"`
+	// DispatcherRules are the rules of the dispatcher, whose format depends on it.
+	DispatcherRules string `json:"dispatcherRules"`
+	// DefaultDelay is the delay, in milliseconds, applied before returning a response.
+	DefaultDelay  int64    `json:"defaultDelay"`
+	ResourcePaths []string `json:"resourcePaths"`
+}
+
+// Ref returns the reference of the service.
+func (s *Service) Ref() ServiceRef {
+	return ServiceRef{Name: s.Name, Version: s.Version}
+}
+
+// Labels returns the labels of the service.
+func (s *Service) Labels() map[string]string {
+	return s.Metadata.Labels
+}
+
+// Operation returns the operation with the given name, if any.
+func (s *Service) Operation(name string) (*Operation, bool) {
+	for i := range s.Operations {
+		if s.Operations[i].Name == name {
+			return &s.Operations[i], true
+		}
+	}
+	return nil, false
+}
+
+// Delay returns the delay applied before returning a response.
+func (o *Operation) Delay() time.Duration {
+	return time.Duration(o.DefaultDelay) * time.Millisecond
+}
+
+// ServiceFilter selects services when listing them. Zero values match every service.
+type ServiceFilter struct {
+	Name    string
+	Version string
+	Type    string
+	// Labels selects services having all these labels.
+	Labels map[string]string
+}
+
+func (f ServiceFilter) matches(s *Service) bool {
+	if (f.Name != "" && f.Name != s.Name) || (f.Version != "" && f.Version != s.Version) || (f.Type != "" && f.Type != s.Type) {
+		return false
+	}
+	for key, value := range f.Labels {
+		if s.Metadata.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ListServices lists the services imported in Microcks that match filter, following pagination.
+func (container *MicrocksContainer) ListServices(ctx context.Context, filter ServiceFilter) ([]Service, error) {
+	var services []Service
+	for page := 0; ; page++ {
+		var pageServices []Service
+		query := url.Values{"page": {strconv.Itoa(page)}, "size": {strconv.Itoa(servicesPageSize)}}
+		if _, err := container.callAPI(ctx, OperationListServices, http.MethodGet, "/services", query, nil, &pageServices); err != nil {
+			return nil, err
+		}
+
+		for i := range pageServices {
+			if filter.matches(&pageServices[i]) {
+				services = append(services, pageServices[i])
+			}
+		}
+		if len(pageServices) < servicesPageSize {
+			return services, nil
+		}
+	}
+}
+
+// GetService gets a service, identified by its name and version, with its operations.
+// It returns an error wrapping ErrServiceNotFound if there's no such service.
+func (container *MicrocksContainer) GetService(ctx context.Context, serviceName string, serviceVersion string) (*Service, error) {
+	services, err := container.ListServices(ctx, ServiceFilter{Name: serviceName, Version: serviceVersion})
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("%s:%s: %w", serviceName, serviceVersion, ErrServiceNotFound)
+	}
+
+	service := &Service{}
+	query := url.Values{"messages": {"false"}}
+	if _, err := container.callAPI(ctx, OperationGetService, http.MethodGet, "/services/"+url.PathEscape(services[0].Id), query, nil, service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// ServiceExists checks that a service, identified by its name and version, has been imported in Microcks.
+func (container *MicrocksContainer) ServiceExists(ctx context.Context, serviceName string, serviceVersion string) (bool, error) {
+	services, err := container.ListServices(ctx, ServiceFilter{Name: serviceName, Version: serviceVersion})
+	if err != nil {
+		return false, err
+	}
+	return len(services) > 0, nil
+}
+
+// DeleteService deletes a service, identified by its name and version, with its mocks.
+// It returns an error wrapping ErrServiceNotFound if there's no such service.
+func (container *MicrocksContainer) DeleteService(ctx context.Context, serviceName string, serviceVersion string) error {
+	service, err := container.GetService(ctx, serviceName, serviceVersion)
+	if err != nil {
+		return err
+	}
+	return container.deleteService(ctx, service.Id)
+}
+
+func (container *MicrocksContainer) deleteService(ctx context.Context, serviceId string) error {
+	_, err := container.callAPI(ctx, OperationDeleteService, http.MethodDelete, "/services/"+url.PathEscape(serviceId), nil, nil, nil)
+	return err
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// snapshotDigestSuffix is appended to the path of a snapshot fixture to store the digest of its sources.
+const snapshotDigestSuffix = ".sha256"
+
+// ExportSnapshot writes a repository snapshot of services to w. This snapshot can then be imported
+// with WithSnapshot or ImportSnapshot. All the services are exported when none is given.
+// It returns an error wrapping ErrServiceNotFound if one of the services doesn't exist.
+func (container *MicrocksContainer) ExportSnapshot(ctx context.Context, w io.Writer, services ...ServiceRef) error {
+	all, err := container.ListServices(ctx, ServiceFilter{})
+	if err != nil {
+		return err
+	}
+
+	var serviceIds []string
+	for _, ref := range services {
+		i := slices.IndexFunc(all, func(s Service) bool { return s.Ref() == ref })
+		if i < 0 {
+			return fmt.Errorf("%s: %w", ref, ErrServiceNotFound)
+		}
+		serviceIds = append(serviceIds, all[i].Id)
+	}
+	if len(services) == 0 {
+		for _, service := range all {
+			serviceIds = append(serviceIds, service.Id)
+		}
+	}
+
+	query := url.Values{"serviceIds": serviceIds}
+	_, err = container.callAPI(ctx, OperationExportSnapshot, http.MethodGet, "/export", query, nil, w)
+	return err
+}
+
+// WithSnapshotFixture speeds up the import of slow artifacts, like remote ones, by saving them as a
+// repository snapshot fixture. sources are main artifacts, either local file paths or remote URLs.
+// When the fixture at snapshotFilePath has been generated from the current sources, it's imported
+// instead of them. Otherwise, sources are imported and the fixture is regenerated with the services
+// imported from them.
+func WithSnapshotFixture(snapshotFilePath string, sources ...string) Option {
+	return func(o *options) error {
+		if len(sources) == 0 {
+			return errors.New("snapshot fixture requires at least one source artifact")
+		}
+		digest, err := sourcesDigest(sources)
+		if err != nil {
+			return err
+		}
+
+		if stored, err := os.ReadFile(snapshotFilePath + snapshotDigestSuffix); err == nil && string(bytes.TrimSpace(stored)) == digest {
+			if _, err := os.Stat(snapshotFilePath); err == nil {
+				o.plan.add(phaseSnapshots, "snapshot "+snapshotFilePath, importSnapshotAction(snapshotFilePath))
+				return nil
+			}
+		}
+
+		for _, source := range sources {
+			if isRemoteArtifact(source) {
+				o.plan.add(phaseMainRemoteArtifacts, "remote artifact "+source, downloadArtifactAction(source, true))
+			} else {
+				o.plan.add(phaseMainArtifacts, "artifact "+source, importArtifactAction(source, true))
+			}
+		}
+		o.plan.add(phaseSnapshotFixtures, "snapshot fixture "+snapshotFilePath, saveSnapshotFixtureAction(snapshotFilePath, sources, digest))
+		return nil
+	}
+}
+
+func saveSnapshotFixtureAction(snapshotFilePath string, sources []string, digest string) postReadyAction {
+	return func(ctx context.Context, container *MicrocksContainer) error {
+		return container.saveSnapshotFixture(ctx, snapshotFilePath, sources, digest)
+	}
+}
+
+// saveSnapshotFixture exports the services imported from sources to snapshotFilePath, along with digest.
+func (container *MicrocksContainer) saveSnapshotFixture(ctx context.Context, snapshotFilePath string, sources []string, digest string) error {
+	services, err := container.ListServices(ctx, ServiceFilter{})
+	if err != nil {
+		return err
+	}
+	var refs []ServiceRef
+	for _, service := range services {
+		if slices.ContainsFunc(sources, func(source string) bool { return isArtifactOf(source, service.SourceArtifact) }) {
+			refs = append(refs, service.Ref())
+		}
+	}
+	if len(refs) == 0 {
+		return errors.New("no service has been imported from the sources")
+	}
+
+	var snapshot bytes.Buffer
+	if err := container.ExportSnapshot(ctx, &snapshot, refs...); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(snapshotFilePath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(snapshotFilePath, snapshot.Bytes(), 0o644); err != nil {
+		return err
+	}
+	// Digest is written last so that an interrupted regeneration is started over.
+	return os.WriteFile(snapshotFilePath+snapshotDigestSuffix, []byte(digest+"\n"), 0o644)
+}
+
+// sourcesDigest computes a digest of the sources of a snapshot fixture: the content of local
+// files, and the URL of remote ones.
+func sourcesDigest(sources []string) (string, error) {
+	hash := sha256.New()
+	for _, source := range sources {
+		fmt.Fprintf(hash, "%s\n", source)
+		if isRemoteArtifact(source) {
+			continue
+		}
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return "", fmt.Errorf("error reading snapshot fixture source: %w", err)
+		}
+		contentHash := sha256.Sum256(content)
+		fmt.Fprintf(hash, "%x\n", contentHash)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isRemoteArtifact(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// isArtifactOf tells if the source artifact of a service, as reported by Microcks, is source.
+func isArtifactOf(source string, sourceArtifact string) bool {
+	if sourceArtifact == "" {
+		return false
+	}
+	if isRemoteArtifact(source) {
+		if sourceArtifact == source {
+			return true
+		}
+		if sourceURL, err := url.Parse(source); err == nil {
+			return path.Base(sourceURL.Path) == sourceArtifact
+		}
+		return false
+	}
+	return filepath.Base(source) == sourceArtifact
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// stubContainer is a container whose Microcks API is served by an httptest server.
+type stubContainer struct {
+	testcontainers.Container
+	endpoint *url.URL
+}
+
+func (c *stubContainer) Host(context.Context) (string, error) {
+	return c.endpoint.Hostname(), nil
+}
+
+func (c *stubContainer) MappedPort(context.Context, nat.Port) (nat.Port, error) {
+	return nat.NewPort("tcp", c.endpoint.Port())
+}
+
+// newStubContainer returns a MicrocksContainer whose API is served by handler, polling every few milliseconds.
+func newStubContainer(t *testing.T, handler http.Handler) *MicrocksContainer {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &MicrocksContainer{
+		Container:      &stubContainer{endpoint: endpoint},
+		pollingBackoff: &Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond},
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// KeepOnFailureEnv is the environment variable that, when set to true, keeps the containers started by
+// RunT alive after a test failure so that they can be inspected. Ryuk must also be disabled
+// (TESTCONTAINERS_RYUK_DISABLED=true) for them to outlive the test process.
+const KeepOnFailureEnv = "MICROCKS_KEEP_ON_FAILURE"
+
+// RunT creates an instance of the MicrocksContainer type for test t. The test fails if the container
+// cannot be started, and the container is terminated at the end of the test. See CleanupT.
+func RunT(t testing.TB, image string, opts ...testcontainers.ContainerCustomizer) *MicrocksContainer {
+	t.Helper()
+
+	microcksContainer, err := Run(context.Background(), image, opts...)
+	if microcksContainer != nil {
+		CleanupT(t, microcksContainer)
+	}
+	if err != nil {
+		t.Fatalf("cannot start Microcks container: %v", err)
+	}
+	return microcksContainer
+}
+
+// CleanupT registers the termination of containers at the end of test t. If the test failed,
+// the logs of the containers are dumped first, and containers are kept alive if KeepOnFailureEnv is true.
+func CleanupT(t testing.TB, containers ...testcontainers.Container) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if t.Failed() {
+			for _, container := range containers {
+				if !isNil(container) {
+					logContainer(t, ctx, container)
+				}
+			}
+			if KeepOnFailure() {
+				t.Logf("%s is set, containers are kept alive", KeepOnFailureEnv)
+				return
+			}
+		}
+
+		for _, container := range containers {
+			if err := testcontainers.TerminateContainer(container); err != nil {
+				t.Errorf("cannot terminate container: %v", err)
+			}
+		}
+	})
+}
+
+// KeepOnFailure tells if containers must be kept alive after a test failure, see KeepOnFailureEnv.
+func KeepOnFailure() bool {
+	keep, _ := strconv.ParseBool(os.Getenv(KeepOnFailureEnv))
+	return keep
+}
+
+// logContainer dumps the logs of a container in the test log.
+func logContainer(t testing.TB, ctx context.Context, container testcontainers.Container) {
+	id := container.GetContainerID()
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	logs, err := container.Logs(ctx)
+	if err != nil {
+		t.Logf("cannot retrieve logs of container %s: %v", id, err)
+		return
+	}
+	defer logs.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(logs); err != nil {
+		t.Logf("cannot read logs of container %s: %v", id, err)
+	}
+	t.Logf("logs of container %s:\n%s", id, buf.String())
+}
+
+// isNil tells if container is nil, including an interface holding a nil pointer.
+func isNil(container testcontainers.Container) bool {
+	if container == nil {
+		return true
+	}
+	value := reflect.ValueOf(container)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
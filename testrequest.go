@@ -0,0 +1,205 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	client "microcks.io/go-client"
+)
+
+// DefaultTestTimeout is the timeout of tests built with NewTestRequest, unless changed.
+const DefaultTestTimeout = 10 * time.Second
+
+var testRunners = []client.TestRunnerType{
+	client.TestRunnerTypeHTTP,
+	client.TestRunnerTypeSOAPHTTP,
+	client.TestRunnerTypeSOAPUI,
+	client.TestRunnerTypePOSTMAN,
+	client.TestRunnerTypeOPENAPISCHEMA,
+	client.TestRunnerTypeASYNCAPISCHEMA,
+	client.TestRunnerTypeGRPCPROTOBUF,
+	client.TestRunnerTypeGRAPHQLSCHEMA,
+}
+
+// TestRequestBuilder builds a client.TestRequest. Create it with NewTestRequest, configure it
+// with its methods and get the request with Build.
+type TestRequestBuilder struct {
+	serviceName    string
+	serviceVersion string
+	runner         client.TestRunnerType
+	endpoint       string
+	timeout        time.Duration
+	operations     []string
+	headers        client.OperationHeaders
+	secretName     string
+	oAuth2         *client.OAuth2ClientContext
+}
+
+// NewTestRequest starts building a test request for a service, identified by its name and version.
+func NewTestRequest(serviceName string, serviceVersion string) *TestRequestBuilder {
+	return &TestRequestBuilder{
+		serviceName:    serviceName,
+		serviceVersion: serviceVersion,
+		timeout:        DefaultTestTimeout,
+	}
+}
+
+// Runner sets the test runner, e.g. client.TestRunnerTypeOPENAPISCHEMA.
+func (b *TestRequestBuilder) Runner(runner client.TestRunnerType) *TestRequestBuilder {
+	b.runner = runner
+	return b
+}
+
+// Endpoint sets the URL of the endpoint to test, as seen from the Microcks container.
+func (b *TestRequestBuilder) Endpoint(endpoint string) *TestRequestBuilder {
+	b.endpoint = endpoint
+	return b
+}
+
+// Timeout sets how long Microcks waits for the test to complete. Microcks works with milliseconds.
+func (b *TestRequestBuilder) Timeout(timeout time.Duration) *TestRequestBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// OnlyOperations restricts the test to the given operations, e.g. "GET /pastries".
+// All the operations of the service are tested by default.
+func (b *TestRequestBuilder) OnlyOperations(operations ...string) *TestRequestBuilder {
+	b.operations = append(b.operations, operations...)
+	return b
+}
+
+// Header adds a header to the requests sent to the tested endpoint for operation.
+// Use "globals" as operation to send it for every operation. Values of a same header are comma separated.
+func (b *TestRequestBuilder) Header(operation string, name string, value string) *TestRequestBuilder {
+	if b.headers == nil {
+		b.headers = make(client.OperationHeaders)
+	}
+	for i, header := range b.headers[operation] {
+		if header.Name == name {
+			b.headers[operation][i].Values += "," + value
+			return b
+		}
+	}
+	b.headers[operation] = append(b.headers[operation], client.HeaderDTO{Name: name, Values: value})
+	return b
+}
+
+// WithSecret sets the name of the Microcks secret used to authenticate against the tested endpoint.
+func (b *TestRequestBuilder) WithSecret(secretName string) *TestRequestBuilder {
+	b.secretName = secretName
+	return b
+}
+
+// WithOAuth2ClientCredentials makes Microcks retrieve a token with the OAuth2 client credentials flow
+// and use it to authenticate against the tested endpoint.
+func (b *TestRequestBuilder) WithOAuth2ClientCredentials(tokenURI string, clientID string, clientSecret string, scopes ...string) *TestRequestBuilder {
+	b.oAuth2 = &client.OAuth2ClientContext{
+		ClientId:     clientID,
+		ClientSecret: clientSecret,
+		TokenUri:     tokenURI,
+		GrantType:    client.OAuth2GrantTypeCLIENTCREDENTIALS,
+	}
+	if len(scopes) > 0 {
+		joined := strings.Join(scopes, " ")
+		b.oAuth2.Scopes = &joined
+	}
+	return b
+}
+
+// Build validates the test request and returns it. All the problems found are reported in the error.
+func (b *TestRequestBuilder) Build() (*client.TestRequest, error) {
+	if err := b.validate(); err != nil {
+		return nil, fmt.Errorf("invalid test request: %w", err)
+	}
+
+	testRequest := &client.TestRequest{
+		ServiceId:    b.serviceName + ":" + b.serviceVersion,
+		RunnerType:   b.runner,
+		TestEndpoint: b.endpoint,
+		Timeout:      int(b.timeout.Milliseconds()),
+	}
+	if len(b.operations) > 0 {
+		operations := slices.Clone(b.operations)
+		testRequest.FilteredOperations = &operations
+	}
+	if len(b.headers) > 0 {
+		headers := make(client.OperationHeaders, len(b.headers))
+		for operation, operationHeaders := range b.headers {
+			headers[operation] = slices.Clone(operationHeaders)
+		}
+		testRequest.OperationsHeaders = &headers
+	}
+	if b.secretName != "" {
+		secretName := b.secretName
+		testRequest.SecretName = &secretName
+	}
+	if b.oAuth2 != nil {
+		oAuth2 := *b.oAuth2
+		testRequest.OAuth2Context = &oAuth2
+	}
+	return testRequest, nil
+}
+
+// TestEndpointWith builds the test request and launches it, see TestEndpoint.
+func (container *MicrocksContainer) TestEndpointWith(ctx context.Context, builder *TestRequestBuilder) (*client.TestResult, error) {
+	testRequest, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return container.TestEndpoint(ctx, testRequest)
+}
+
+func (b *TestRequestBuilder) validate() error {
+	var errs []error
+	if b.serviceName == "" || b.serviceVersion == "" {
+		errs = append(errs, errors.New("service name and version are required"))
+	}
+	if b.runner == "" {
+		errs = append(errs, errors.New("runner is required"))
+	} else if !slices.Contains(testRunners, b.runner) {
+		errs = append(errs, fmt.Errorf("unknown runner %q", b.runner))
+	}
+	if endpoint, err := url.Parse(b.endpoint); b.endpoint == "" || err != nil || !endpoint.IsAbs() {
+		errs = append(errs, fmt.Errorf("endpoint %q is not an absolute URL", b.endpoint))
+	}
+	if b.timeout < time.Millisecond {
+		errs = append(errs, fmt.Errorf("timeout %s is too short", b.timeout))
+	}
+	if len(b.operations) > 0 {
+		for operation := range b.headers {
+			if operation != "globals" && !slices.Contains(b.operations, operation) {
+				errs = append(errs, fmt.Errorf("headers are set for operation %q that is not tested", operation))
+			}
+		}
+	}
+	if b.oAuth2 != nil {
+		if b.oAuth2.ClientId == "" || b.oAuth2.ClientSecret == "" {
+			errs = append(errs, errors.New("OAuth2 client id and secret are required"))
+		}
+		if tokenURI, err := url.Parse(b.oAuth2.TokenUri); err != nil || !tokenURI.IsAbs() {
+			errs = append(errs, fmt.Errorf("OAuth2 token URI %q is not an absolute URL", b.oAuth2.TokenUri))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OperationVerification verifies the invocations of a mocked operation, for the current date.
+// Build it with VerifyOperation then call one of Times, AtLeast or Never.
+type OperationVerification struct {
+	container      *MicrocksContainer
+	serviceName    string
+	serviceVersion string
+	operation      string
+}
+
+// VerificationError is returned when an operation has not been invoked as expected.
+// It holds the counts observed for each invoked operation of the service.
+type VerificationError struct {
+	ServiceName    string
+	ServiceVersion string
+	Operation      string
+	// Expected describes the expectation, e.g. "exactly 2 times".
+	Expected string
+	// Observed holds the invocations' count of each operation of the service.
+	Observed map[string]int
+}
+
+func (e *VerificationError) Error() string {
+	operations := make([]string, 0, len(e.Observed))
+	for operation := range e.Observed {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	var observed strings.Builder
+	for _, operation := range operations {
+		fmt.Fprintf(&observed, "\n  %s: %d", operation, e.Observed[operation])
+	}
+	return fmt.Sprintf("expected %q of %s:%s to be invoked %s but was invoked %d times; observed invocations:%s",
+		e.Operation, e.ServiceName, e.ServiceVersion, e.Expected, e.Observed[e.Operation], observed.String())
+}
+
+// VerifyOperation starts the verification of the invocations of an operation of a given service,
+// identified by its name and version. operation is the operation name, e.g. "GET /pastries/{name}".
+func (container *MicrocksContainer) VerifyOperation(serviceName string, serviceVersion string, operation string) *OperationVerification {
+	return &OperationVerification{
+		container:      container,
+		serviceName:    serviceName,
+		serviceVersion: serviceVersion,
+		operation:      operation,
+	}
+}
+
+// Times checks the operation has been invoked exactly n times.
+// It gives Microcks a chance to process recent invocations before failing.
+func (v *OperationVerification) Times(ctx context.Context, n int) error {
+	return v.verify(ctx, fmt.Sprintf("exactly %d times", n), n, func(count int) bool { return count == n })
+}
+
+// AtLeast checks the operation has been invoked at least n times.
+// It gives Microcks a chance to process recent invocations before failing.
+func (v *OperationVerification) AtLeast(ctx context.Context, n int) error {
+	return v.verify(ctx, fmt.Sprintf("at least %d times", n), n, func(count int) bool { return count >= n })
+}
+
+// Never checks the operation has not been invoked so far. Contrary to Times and AtLeast, it does not
+// wait for the invocations settle timeout.
+func (v *OperationVerification) Never(ctx context.Context) error {
+	return v.verify(ctx, "0 times", 0, func(count int) bool { return count == 0 })
+}
+
+// verify waits for the operation count to reach awaited, then checks it matches the expectation.
+func (v *OperationVerification) verify(ctx context.Context, expected string, awaited int, matches func(count int) bool) error {
+	var counts map[string]int
+	_, err := v.container.settledCount(ctx, awaited, func(ctx context.Context) (int, error) {
+		current, err := v.container.operationInvocationsCounts(ctx, v.serviceName, v.serviceVersion, time.Now())
+		if err != nil {
+			return 0, err
+		}
+		counts = current
+		return counts[v.operation], nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot retrieve invocations stats: %w", err)
+	}
+
+	if matches(counts[v.operation]) {
+		return nil
+	}
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	if _, ok := counts[v.operation]; !ok {
+		counts[v.operation] = 0
+	}
+	return &VerificationError{
+		ServiceName:    v.serviceName,
+		ServiceVersion: v.serviceVersion,
+		Operation:      v.operation,
+		Expected:       expected,
+		Observed:       counts,
+	}
+}
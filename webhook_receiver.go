@@ -0,0 +1,313 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+	"gopkg.in/yaml.v3"
+	"microcks.io/testcontainers-go/internal/jsonschema"
+)
+
+// WebhookReceiver is an HTTP server, running on the host, that receives the events pushed by Microcks
+// webhooks. Use Webhook to get the coordinates to register: WithWebhookRegistration then exposes the
+// receiver to the container.
+type WebhookReceiver struct {
+	server         *httptest.Server
+	validateSchema bool
+
+	mu       sync.Mutex
+	targets  int
+	schemas  map[string]webhookSchema
+	messages []webhookMessage
+	arrived  chan struct{}
+}
+
+// WebhookReceiverOption configures a WebhookReceiver.
+type WebhookReceiverOption func(*WebhookReceiver)
+
+// webhookSchema is the schema of the events pushed by a webhook, with the specification
+// holding it to resolve references.
+type webhookSchema struct {
+	document any
+	schema   any
+}
+
+type webhookMessage struct {
+	body []byte
+	// invalid holds the schema violations of body, if any.
+	invalid error
+}
+
+// WithSchemaValidation checks the received events hold the properties required by the schema of the
+// webhook operation, found in the service specification. Other schema keywords are not checked.
+// Missing properties are reported by AssertEach.
+func WithSchemaValidation() WebhookReceiverOption {
+	return func(r *WebhookReceiver) {
+		r.validateSchema = true
+	}
+}
+
+// NewWebhookReceiver starts a WebhookReceiver. Close it once done.
+func NewWebhookReceiver(opts ...WebhookReceiverOption) *WebhookReceiver {
+	receiver := &WebhookReceiver{
+		schemas: map[string]webhookSchema{},
+		arrived: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(receiver)
+	}
+	receiver.server = httptest.NewServer(http.HandlerFunc(receiver.receive))
+	return receiver
+}
+
+// Close shuts the receiver down.
+func (r *WebhookReceiver) Close() {
+	r.server.Close()
+}
+
+// Port returns the port the receiver listens on, on the host.
+func (r *WebhookReceiver) Port() int {
+	serverURL, _ := url.Parse(r.server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	return port
+}
+
+// Webhook returns the coordinates to register so that Microcks pushes the events of a webhook
+// operation to this receiver. Each call targets a distinct path.
+func (r *WebhookReceiver) Webhook(serviceId string, operationName string) WebhookCoordinates {
+	r.mu.Lock()
+	r.targets++
+	path := fmt.Sprintf("/webhooks/%d", r.targets)
+	r.mu.Unlock()
+
+	return WebhookCoordinates{
+		ServiceId:     serviceId,
+		OperationName: operationName,
+		TargetUrl:     fmt.Sprintf("http://%s:%d%s", testcontainers.HostInternal, r.Port(), path),
+		receiver:      r,
+	}
+}
+
+func (r *WebhookReceiver) receive(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message := webhookMessage{body: body}
+	if schema, ok := r.schemas[req.URL.Path]; ok {
+		var value any
+		if err := json.Unmarshal(body, &value); err != nil {
+			message.invalid = fmt.Errorf("invalid JSON: %w", err)
+		} else {
+			message.invalid = jsonschema.CheckRequired(schema.document, schema.schema, value)
+		}
+	}
+	r.messages = append(r.messages, message)
+	close(r.arrived)
+	r.arrived = make(chan struct{})
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Messages returns the bodies of the events received so far.
+func (r *WebhookReceiver) Messages() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies()
+}
+
+func (r *WebhookReceiver) bodies() [][]byte {
+	bodies := make([][]byte, len(r.messages))
+	for i, message := range r.messages {
+		bodies[i] = message.body
+	}
+	return bodies
+}
+
+// Await waits until at least n events have been received and returns their bodies.
+// On ctx expiry, the returned error tells how many were received.
+func (r *WebhookReceiver) Await(ctx context.Context, n int) ([][]byte, error) {
+	for {
+		r.mu.Lock()
+		received, arrived := len(r.messages), r.arrived
+		if received >= n {
+			bodies := r.bodies()
+			r.mu.Unlock()
+			return bodies, nil
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-arrived:
+		case <-ctx.Done():
+			return r.Messages(), fmt.Errorf("received %d of %d webhook messages: %w", received, n, ctx.Err())
+		}
+	}
+}
+
+// AssertEach checks every received event with check, that may be nil, and against its schema when
+// validation is enabled. All the failures are reported in the error, as well as the absence of events.
+func (r *WebhookReceiver) AssertEach(check func(body []byte) error) error {
+	r.mu.Lock()
+	messages := append([]webhookMessage(nil), r.messages...)
+	r.mu.Unlock()
+
+	if len(messages) == 0 {
+		return errors.New("no webhook message received")
+	}
+	var errs []error
+	for i, message := range messages {
+		if message.invalid != nil {
+			errs = append(errs, fmt.Errorf("message %d misses properties required by the webhook schema: %w", i, message.invalid))
+		}
+		if check != nil {
+			if err := check(message.body); err != nil {
+				errs = append(errs, fmt.Errorf("message %d: %w", i, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// prepare loads the schema of the webhook operation targeting the receiver, when validation is enabled.
+func (r *WebhookReceiver) prepare(ctx context.Context, container *MicrocksContainer, coordinates WebhookCoordinates) error {
+	if !r.validateSchema {
+		return nil
+	}
+	target, err := url.Parse(coordinates.TargetUrl)
+	if err != nil {
+		return err
+	}
+	schema, err := container.webhookSchema(ctx, coordinates)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[target.Path] = schema
+	return nil
+}
+
+// serviceResource is a resource, like a specification, attached to a service.
+type serviceResource struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// webhookSchema finds the schema of the events pushed by a webhook operation in the OpenAPI
+// specification of its service.
+func (container *MicrocksContainer) webhookSchema(ctx context.Context, coordinates WebhookCoordinates) (webhookSchema, error) {
+	ref, err := ParseServiceRef(coordinates.ServiceId)
+	if err != nil {
+		return webhookSchema{}, err
+	}
+	service, err := container.GetService(ctx, ref.Name, ref.Version)
+	if err != nil {
+		return webhookSchema{}, err
+	}
+
+	var resources []serviceResource
+	if _, err := container.callAPI(ctx, OperationGetServiceResources, http.MethodGet, "/resources/service/"+url.PathEscape(service.Id), nil, nil, &resources); err != nil {
+		return webhookSchema{}, err
+	}
+	for _, resource := range resources {
+		if resource.Type != "OPEN_API_SPEC" {
+			continue
+		}
+		var document map[string]any
+		if err := yaml.Unmarshal([]byte(resource.Content), &document); err != nil {
+			return webhookSchema{}, fmt.Errorf("cannot parse %s: %w", resource.Name, err)
+		}
+		if schema, ok := findWebhookSchema(document, coordinates.OperationName); ok {
+			return webhookSchema{document: document, schema: schema}, nil
+		}
+	}
+	return webhookSchema{}, fmt.Errorf("no schema found for webhook %s on %s", coordinates.OperationName, coordinates.ServiceId)
+}
+
+// findWebhookSchema finds the JSON request body schema of a webhook operation. Microcks names these
+// operations after the webhook and, possibly, the method, e.g. "newPet WEBHOOK".
+func findWebhookSchema(document map[string]any, operationName string) (any, bool) {
+	webhooks, _ := document["webhooks"].(map[string]any)
+	words := strings.Fields(operationName)
+	for _, word := range words {
+		pathItem, ok := webhooks[word].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// Prefer the method named by the operation, then the first one having a request body.
+		methods := make([]string, 0, len(pathItem))
+		for method := range pathItem {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		sort.SliceStable(methods, func(i, j int) bool {
+			return containsFold(words, methods[i]) && !containsFold(words, methods[j])
+		})
+		for _, method := range methods {
+			operation, _ := pathItem[method].(map[string]any)
+			requestBody, _ := operation["requestBody"].(map[string]any)
+			content, _ := requestBody["content"].(map[string]any)
+			// Prefer application/json, then the other JSON media types in order.
+			mediaTypes := make([]string, 0, len(content))
+			for mediaType := range content {
+				if strings.Contains(mediaType, "json") {
+					mediaTypes = append(mediaTypes, mediaType)
+				}
+			}
+			sort.Strings(mediaTypes)
+			sort.SliceStable(mediaTypes, func(i, j int) bool {
+				return mediaTypes[i] == "application/json" && mediaTypes[j] != "application/json"
+			})
+			for _, mediaType := range mediaTypes {
+				mediaObject, _ := content[mediaType].(map[string]any)
+				if schema, ok := mediaObject["schema"]; ok {
+					return schema, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+func containsFold(words []string, s string) bool {
+	for _, word := range words {
+		if strings.EqualFold(word, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright The Microcks Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package microcks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	client "microcks.io/go-client"
+)
+
+// Webhook is a webhook registered in Microcks.
+type Webhook struct {
+	Id string `json:"id"`
+	// OperationId identifies the webhook operation as "<service id>-<operation name>".
+	OperationId string `json:"operationId"`
+	TargetUrl   string `json:"targetUrl"`
+	// Frequency is the interval, in milliseconds, between two events pushed to TargetUrl.
+	Frequency int64 `json:"frequency"`
+	// ExpiresAt is the time, in milliseconds since epoch, after which no more events are pushed.
+	ExpiresAt int64 `json:"expiresAt"`
+	// ErrorCountThreshold is the number of failed pushes after which the webhook is disabled.
+	ErrorCountThreshold int `json:"errorCountThreshold"`
+}
+
+// Interval returns the interval between two events pushed to the target URL.
+func (w *Webhook) Interval() time.Duration {
+	return time.Duration(w.Frequency) * time.Millisecond
+}
+
+// Expiration returns the time after which no more events are pushed to the target URL.
+func (w *Webhook) Expiration() time.Time {
+	return time.UnixMilli(w.ExpiresAt)
+}
+
+// RegisterWebhook registers a webhook in Microcks and returns its identifier, to be used
+// with UnregisterWebhook.
+func (container *MicrocksContainer) RegisterWebhook(ctx context.Context, coordinates WebhookCoordinates) (string, error) {
+	ref, err := ParseServiceRef(coordinates.ServiceId)
+	if err != nil {
+		return "", err
+	}
+
+	// Find the correct technical serviceId from the functional "name:version".
+	service, err := container.GetService(ctx, ref.Name, ref.Version)
+	if err != nil {
+		return "", fmt.Errorf("error looking up service: %w", err)
+	}
+	operationId := service.Id + "-" + coordinates.OperationName
+
+	request, err := coordinates.registrationRequest(operationId)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook %s on %s: %w", coordinates.OperationName, coordinates.ServiceId, err)
+	}
+	if coordinates.receiver != nil {
+		if err := coordinates.receiver.prepare(ctx, container, coordinates); err != nil {
+			return "", err
+		}
+	}
+
+	c, err := container.Client(ctx)
+	if err != nil {
+		return "", &ConnectionError{Operation: OperationRegisterWebhook, Err: err}
+	}
+	response, err := c.RegisterWebhook(ctx, request)
+	if err != nil {
+		return "", &ConnectionError{Operation: OperationRegisterWebhook, Err: err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		return "", newAPIError(OperationRegisterWebhook, "", response)
+	}
+
+	// The registration is usually returned, otherwise find it among the registered webhooks.
+	var registered Webhook
+	if body, err := io.ReadAll(response.Body); err == nil && json.Unmarshal(body, &registered) == nil && registered.Id != "" {
+		return registered.Id, nil
+	}
+	webhooks, err := container.ListWebhooks(ctx)
+	if err != nil {
+		return "", err
+	}
+	for i := len(webhooks) - 1; i >= 0; i-- {
+		if webhooks[i].OperationId == operationId && webhooks[i].TargetUrl == coordinates.TargetUrl {
+			return webhooks[i].Id, nil
+		}
+	}
+	return "", fmt.Errorf("webhook %s on %s is registered but cannot be found", coordinates.OperationName, coordinates.ServiceId)
+}
+
+// registrationRequest validates coordinates and converts them to a registration request.
+func (coordinates WebhookCoordinates) registrationRequest(operationId string) (client.WebhookRegistrationRequest, error) {
+	var errs []error
+	if target, err := url.Parse(coordinates.TargetUrl); coordinates.TargetUrl == "" || err != nil || !target.IsAbs() {
+		errs = append(errs, fmt.Errorf("target %q is not an absolute URL", coordinates.TargetUrl))
+	}
+	if coordinates.Frequency < 0 || (coordinates.Frequency > 0 && coordinates.Frequency < time.Millisecond) {
+		errs = append(errs, fmt.Errorf("frequency %s is too short", coordinates.Frequency))
+	}
+	if !coordinates.ExpiresAt.IsZero() && coordinates.ExpiresAt.Before(time.Now()) {
+		errs = append(errs, fmt.Errorf("expiry %s is in the past", coordinates.ExpiresAt))
+	}
+	if coordinates.ErrorCountThreshold < 0 {
+		errs = append(errs, fmt.Errorf("error count threshold %d is negative", coordinates.ErrorCountThreshold))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return client.WebhookRegistrationRequest{}, err
+	}
+
+	// Unset tuning fields are left nil so that Microcks applies its own defaults.
+	request := client.WebhookRegistrationRequest{
+		OperationId: operationId,
+		TargetUrl:   coordinates.TargetUrl,
+	}
+	if coordinates.Frequency > 0 {
+		frequency := int(coordinates.Frequency.Milliseconds())
+		request.Frequency = &frequency
+	}
+	if !coordinates.ExpiresAt.IsZero() {
+		expiresAt := coordinates.ExpiresAt.UnixMilli()
+		request.ExpiresAt = &expiresAt
+	}
+	if coordinates.ErrorCountThreshold > 0 {
+		errorCountThreshold := coordinates.ErrorCountThreshold
+		request.ErrorCountThreshold = &errorCountThreshold
+	}
+	return request, nil
+}
+
+// ListWebhooks lists the webhooks registered in Microcks.
+func (container *MicrocksContainer) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if _, err := container.callAPI(ctx, OperationListWebhooks, http.MethodGet, "/webhooks", nil, nil, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// UnregisterWebhook unregisters a webhook, identified by its Microcks identifier.
+func (container *MicrocksContainer) UnregisterWebhook(ctx context.Context, webhookId string) error {
+	_, err := container.callAPI(ctx, OperationUnregisterWebhook, http.MethodDelete, "/webhooks/"+url.PathEscape(webhookId), nil, nil, nil)
+	return err
+}